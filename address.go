@@ -0,0 +1,47 @@
+package i2clcd
+
+import "machine"
+
+const (
+	// AddrPCF8574 is the default address of PCF8574 backpacks (A0-A2 open)
+	AddrPCF8574 = 0x27
+	// AddrPCF8574A is the default address of PCF8574A backpacks (A0-A2 open)
+	AddrPCF8574A = 0x3F
+)
+
+// Return every address a PCF8574 or PCF8574A backpack can be jumpered to,
+// with the two factory defaults first
+func CommonAddresses() []uint8 {
+	addrs := []uint8{AddrPCF8574, AddrPCF8574A}
+	for a := uint8(0x20); a < AddrPCF8574; a++ {
+		addrs = append(addrs, a)
+	}
+	for a := uint8(0x38); a < AddrPCF8574A; a++ {
+		addrs = append(addrs, a)
+	}
+	return addrs
+}
+
+// Return the first of candidates that acknowledges a write on bus, or
+// ErrNoDevice if none do. A nil candidates list tries CommonAddresses. Each
+// probe writes 0x00, leaving the enable line low and the backlight off on a
+// backpack that answers, so call Init afterwards.
+func Detect(bus *machine.I2C, candidates []uint8) (uint8, error) {
+	if candidates == nil {
+		candidates = CommonAddresses()
+	}
+	for _, addr := range candidates {
+		if bus.Tx(uint16(addr), []byte{0x00}, nil) == nil {
+			return addr, nil
+		}
+	}
+	return 0, ErrNoDevice
+}
+
+// Check the backpack still acknowledges at the configured address by
+// rewriting the current backlight state, returning the bus error if not
+func (lcd *I2CLCD) Ping() error {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	return lcd.expanderWrite(0x00)
+}
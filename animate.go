@@ -0,0 +1,395 @@
+package i2clcd
+
+import (
+	"strconv"
+	"time"
+)
+
+// Run step in a background goroutine until the returned stop function is
+// called. Each call receives an incrementing frame counter, runs with the
+// animation lock held, and returns how long to wait before the next frame, or
+// a negative duration to end the animation. Stop blocks until the goroutine
+// has exited, so no frame is drawn after it returns.
+func (lcd *I2CLCD) animate(step func(frame int) time.Duration) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		for frame := 0; ; frame++ {
+			lcd.mu.Lock()
+			delay := step(frame)
+			lcd.mu.Unlock()
+			if delay < 0 {
+				return
+			}
+
+			select {
+			case <-done:
+				return
+			case <-time.After(delay):
+			}
+		}
+	}()
+
+	stopped := false
+	return func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		close(done)
+		<-exited
+	}
+}
+
+// Scroll lines upwards through the whole display one row per interval,
+// looping once they have scrolled off the top. Only rows whose text changes
+// are repainted. Returns a function that stops the scroll.
+func (lcd *I2CLCD) ScrollCredits(lines []string, interval time.Duration) (stop func()) {
+	if lcd.noArea() {
+		return func() {}
+	}
+	buf := make([]string, 0, len(lines)+int(lcd.rows))
+	for _, line := range lines {
+		buf = append(buf, fitText(line, int(lcd.cols)))
+	}
+	for i := 0; i < int(lcd.rows); i++ {
+		buf = append(buf, spaces(int(lcd.cols)))
+	}
+	painted := make([]string, lcd.rows)
+
+	return lcd.animate(func(frame int) time.Duration {
+		top := frame % len(buf)
+		for row := range painted {
+			line := buf[(top+row)%len(buf)]
+			if painted[row] == line {
+				continue
+			}
+			lcd.SetCursor(0, uint8(row))
+			lcd.Print(line)
+			painted[row] = line
+		}
+		return interval
+	})
+}
+
+// Word-wrap text to the display width and, if it needs more rows than the
+// display has, scroll it up one line every linePause, jumping back to the top
+// after the last line has been shown. Text that fits is printed once and left
+// in place. Returns a function that stops the scroll.
+func (lcd *I2CLCD) PrintScrolling(text string, linePause time.Duration) (stop func()) {
+	if lcd.noArea() {
+		return func() {}
+	}
+	lines := wrapText(text, int(lcd.cols))
+	for len(lines) < int(lcd.rows) {
+		lines = append(lines, "")
+	}
+	positions := len(lines) - int(lcd.rows) + 1
+
+	return lcd.animate(func(frame int) time.Duration {
+		top := frame % positions
+		for row := 0; row < int(lcd.rows); row++ {
+			lcd.SetCursor(0, uint8(row))
+			lcd.Print(fitText(lines[top+row], int(lcd.cols)))
+		}
+		if positions == 1 {
+			return -1
+		}
+		return linePause
+	})
+}
+
+// Maximum number of intermediate values TweenInt draws
+const tweenMaxSteps = 30
+
+// Count a number at (col, row) from from to to over d, printing the
+// intermediate values. Digits left over when the number gets shorter are
+// blanked.
+func (lcd *I2CLCD) TweenInt(col, row uint8, from, to int, d time.Duration) {
+	steps := to - from
+	if steps < 0 {
+		steps = -steps
+	}
+	if steps > tweenMaxSteps {
+		steps = tweenMaxSteps
+	}
+
+	width := 0
+	for i := 0; i <= steps; i++ {
+		value := to
+		if steps > 0 {
+			value = from + (to-from)*i/steps
+		}
+		text := strconv.Itoa(value)
+		lcd.SetCursor(col, row)
+		lcd.Print(text + spaces(width-len(text)))
+		width = len(text)
+
+		if i < steps {
+			lcd.sleep(d / time.Duration(steps))
+		}
+	}
+}
+
+// How long blinking spans stay visible and hidden
+const spanBlinkInterval = 500 * time.Millisecond
+
+// Span is a run of text printed by PrintSpans
+type Span struct {
+	Text  string
+	Blink bool
+}
+
+// Lay out spans one after another across row, padding the rest of the row,
+// and blink the spans marked Blink in the background while the rest stays
+// static. Returns a function that stops the blinking and leaves the spans
+// visible.
+func (lcd *I2CLCD) PrintSpans(row uint8, spans []Span) (stop func()) {
+	type region struct {
+		col  uint8
+		text string
+	}
+	var line string
+	var blinking []region
+	for _, span := range spans {
+		text := truncateCells(span.Text, int(lcd.cols)-cellCount(line))
+		if span.Blink && text != "" {
+			blinking = append(blinking, region{uint8(cellCount(line)), text})
+		}
+		line += text
+	}
+	lcd.SetCursor(0, row)
+	lcd.Print(fitText(line, int(lcd.cols)))
+
+	if len(blinking) == 0 {
+		return func() {}
+	}
+	stopBlink := lcd.animate(func(frame int) time.Duration {
+		for _, r := range blinking {
+			lcd.SetCursor(r.col, row)
+			if frame%2 == 1 {
+				lcd.Print(spaces(cellCount(r.text)))
+			} else {
+				lcd.Print(r.text)
+			}
+		}
+		return spanBlinkInterval
+	})
+	return func() {
+		stopBlink()
+		for _, r := range blinking {
+			lcd.SetCursor(r.col, row)
+			lcd.Print(r.text)
+		}
+	}
+}
+
+// Alternate the whole screen between frameA and frameB every interval,
+// sending only the cells that differ between them. Returns a function that
+// stops the animation.
+func (lcd *I2CLCD) AlternateFrames(frameA, frameB [][]rune, interval time.Duration) (stop func()) {
+	return lcd.animate(func(frame int) time.Duration {
+		if frame%2 == 0 {
+			lcd.queueGrid(frameA)
+		} else {
+			lcd.queueGrid(frameB)
+		}
+		lcd.Flush()
+		return interval
+	})
+}
+
+// Heartbeat pulse frames, a dot growing and shrinking
+var heartbeatGlyphs = [4][8]byte{
+	{0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00},
+	{0x00, 0x00, 0x04, 0x0E, 0x04, 0x00, 0x00, 0x00},
+	{0x00, 0x0E, 0x1F, 0x1F, 0x1F, 0x0E, 0x00, 0x00},
+	{0x00, 0x00, 0x04, 0x0E, 0x04, 0x00, 0x00, 0x00},
+}
+
+// Pulse a dot at (col, row) every interval to show the firmware is alive.
+// The cell is written once and the pulse is animated by reprogramming a
+// single free CGRAM location, held until the pulse stops. The pulse also
+// ends if the location is taken away, e.g. by Clear or CreateChar. Returns a
+// function that stops the pulse, or ErrGlyphInUse if no location is free.
+func (lcd *I2CLCD) StartHeartbeat(col, row uint8, interval time.Duration) (stop func(), err error) {
+	slots, err := lcd.allocGlyphs(ownerHeartbeat, 1)
+	if err != nil {
+		return func() {}, err
+	}
+	slot := slots[0]
+	lcd.loadGlyph(slot, heartbeatGlyphs[0][:])
+	lcd.PutChar(col, row, slot)
+
+	stopPulse := lcd.animate(func(frame int) time.Duration {
+		if lcd.glyphOwners[slot] != ownerHeartbeat {
+			return -1
+		}
+		lcd.loadGlyph(slot, heartbeatGlyphs[frame%len(heartbeatGlyphs)][:])
+		// Leave the controller addressing DDRAM at the tracked cursor
+		lcd.moveCursor(lcd.curCol, lcd.curRow)
+		return interval
+	})
+	return func() {
+		stopPulse()
+		lcd.mu.Lock()
+		defer lcd.mu.Unlock()
+		if lcd.glyphOwners[slot] == ownerHeartbeat {
+			lcd.glyphOwners[slot] = ownerNone
+		}
+	}, nil
+}
+
+// Blink a clock colon at (col, row), alternating between ':' and a space
+// every interval without touching the surrounding digits. Returns a function
+// that stops the blinking and leaves the colon shown.
+func (lcd *I2CLCD) BlinkColon(col, row uint8, interval time.Duration) (stop func()) {
+	stopBlink := lcd.animate(func(frame int) time.Duration {
+		if frame%2 == 0 {
+			lcd.PutChar(col, row, ':')
+		} else {
+			lcd.PutChar(col, row, ' ')
+		}
+		return interval
+	})
+	return func() {
+		stopBlink()
+		lcd.PutChar(col, row, ':')
+	}
+}
+
+// Play full-screen frames at fps frames per second, sending only the cells
+// that change from one frame to the next. Without loop the last frame stays
+// on screen once reached. Returns a function that stops playback.
+func (lcd *I2CLCD) PlayFrames(frames [][][]rune, fps int, loop bool) (stop func()) {
+	if len(frames) == 0 {
+		return func() {}
+	}
+	if fps < 1 {
+		fps = 1
+	}
+	interval := time.Second / time.Duration(fps)
+	return lcd.animate(func(frame int) time.Duration {
+		if !loop && frame >= len(frames) {
+			return -1
+		}
+		lcd.queueGrid(frames[frame%len(frames)])
+		lcd.Flush()
+		return interval
+	})
+}
+
+// Show label at (col, row) followed by zero to three dots, adding a dot every
+// interval and starting over after the third. Positions of dots no longer
+// shown are blanked. Returns a function that stops the animation.
+func (lcd *I2CLCD) LoadingDots(col, row uint8, label string, interval time.Duration) (stop func()) {
+	return lcd.animate(func(frame int) time.Duration {
+		dots := frame % 4
+		lcd.SetCursor(col, row)
+		lcd.Print(label + "..."[:dots] + spaces(3-dots))
+		return interval
+	})
+}
+
+// Flash the display off and on count times to draw attention, blocking until
+// done. The first flash lasts startInterval off and startInterval on, and
+// each following one is decay times longer than the one before, so a decay
+// above 1 settles down gradually. Contents and the blank-on-off setting are
+// left untouched, and the display ends on.
+func (lcd *I2CLCD) AttentionFlash(count int, startInterval time.Duration, decay float64) {
+	if decay <= 0 {
+		decay = 1
+	}
+	lcd.display = true
+	interval := startInterval
+	for i := 0; i < count; i++ {
+		lcd.sendCommand(lcd.displayControl() &^ LCD_DISPLAYON)
+		lcd.sleep(interval)
+		lcd.sendCommand(lcd.displayControl())
+		lcd.sleep(interval)
+		interval = time.Duration(float64(interval) * decay)
+	}
+	lcd.sendCommand(lcd.displayControl())
+}
+
+// Length of one software PWM cycle used by BreatheBacklight
+const breathePWMPeriod = 10 * time.Millisecond
+
+// Fade the backlight smoothly up and down once every period by switching it
+// with software PWM, for an idle or sleep indicator. The backlight has no
+// dimming input of its own, so the brightness steps depend on how
+// accurately the target can time the PWM cycle. Returns a function that
+// stops the effect and restores the backlight's previous state.
+func (lcd *I2CLCD) BreatheBacklight(period time.Duration) (stop func()) {
+	was := lcd.BacklightOn()
+	if period < 2*breathePWMPeriod {
+		period = 2 * breathePWMPeriod
+	}
+	start := time.Now()
+	var on time.Duration
+
+	stopBreathe := lcd.animate(func(frame int) time.Duration {
+		if frame%2 == 1 {
+			lcd.setBacklight(false)
+			return breathePWMPeriod - on
+		}
+		// Duty rises linearly over the first half of the period and falls
+		// over the second
+		t := time.Since(start) % period
+		if t > period/2 {
+			t = period - t
+		}
+		on = breathePWMPeriod * 2 * t / period
+		if on > 0 {
+			lcd.setBacklight(true)
+		}
+		return on
+	})
+	return func() {
+		stopBreathe()
+		lcd.setBacklight(was)
+	}
+}
+
+// Call fn with the display once d has passed. fn runs with the animation
+// lock held, so it never races an animation frame, and must not call an
+// animation's stop function; use AfterUnlocked for that. Returns a function
+// that cancels the call if it has not happened yet and otherwise waits for
+// it to finish, so it must not be called from fn itself.
+func (lcd *I2CLCD) After(d time.Duration, fn func(*I2CLCD)) (cancel func()) {
+	return lcd.AfterUnlocked(d, func(lcd *I2CLCD) {
+		lcd.mu.Lock()
+		defer lcd.mu.Unlock()
+		fn(lcd)
+	})
+}
+
+// Like After, but fn runs without the animation lock, so it may stop other
+// animations, for example to replace a LoadingDots with a result. Stop any
+// animation drawing to the display before fn draws there.
+func (lcd *I2CLCD) AfterUnlocked(d time.Duration, fn func(*I2CLCD)) (cancel func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		select {
+		case <-done:
+			return
+		case <-time.After(d):
+		}
+		fn(lcd)
+	}()
+
+	cancelled := false
+	return func() {
+		if cancelled {
+			return
+		}
+		cancelled = true
+		close(done)
+		<-exited
+	}
+}
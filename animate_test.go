@@ -0,0 +1,49 @@
+package i2clcd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAfterCallbackStopsAnimation(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	stopDots := lcd.LoadingDots(0, 0, "Wait", 0)
+	called := make(chan struct{})
+	cancel := lcd.AfterUnlocked(5*time.Millisecond, func(lcd *I2CLCD) {
+		// Give the dots goroutine time to start waiting for its next frame
+		time.Sleep(20 * time.Millisecond)
+		stopDots()
+		lcd.PrintAt(0, 0, "Done")
+		close(called)
+	})
+
+	select {
+	case <-called:
+	case <-time.After(2 * time.Second):
+		t.Fatal("After callback deadlocked stopping an animation")
+	}
+	cancel()
+	if got := string(lcd.shadow[0][:4]); got != "Done" {
+		t.Fatalf("row 0 = %q, want %q", got, "Done")
+	}
+}
+
+func TestAfterSerializesWithFrames(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	stopDots := lcd.LoadingDots(0, 0, "Wait", 0)
+	defer stopDots()
+	called := make(chan struct{})
+	cancel := lcd.After(time.Millisecond, func(lcd *I2CLCD) {
+		defer close(called)
+		for i := 0; i < 50; i++ {
+			lcd.PrintAt(0, 1, "Done")
+		}
+	})
+	defer cancel()
+
+	select {
+	case <-called:
+	case <-time.After(2 * time.Second):
+		t.Fatal("After callback never ran")
+	}
+}
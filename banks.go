@@ -0,0 +1,42 @@
+package i2clcd
+
+// Define a named bank of up to 8 glyphs for LoadBank, replacing any bank of
+// the same name. Glyph i is loaded into CGRAM location i. Redefining the
+// loaded bank does not reload it.
+func (lcd *I2CLCD) DefineBank(name string, glyphs [][8]byte) error {
+	if len(glyphs) > 8 {
+		return ErrTooManyGlyphs
+	}
+	if lcd.banks == nil {
+		lcd.banks = make(map[string][][8]byte)
+	}
+	lcd.banks[name] = append([][8]byte(nil), glyphs...)
+	return nil
+}
+
+// Load the glyphs of the named bank into CGRAM, leaving the cursor where it
+// was. Characters of the previous bank already on screen change to the new
+// glyphs in the same locations. The bank is reloaded automatically by Reset.
+// On a bus error the bank is left partly loaded and not made active.
+func (lcd *I2CLCD) LoadBank(name string) error {
+	glyphs, ok := lcd.banks[name]
+	if !ok {
+		return ErrUnknownBank
+	}
+	for i, g := range glyphs {
+		lcd.claimUserGlyph(byte(i))
+		if err := lcd.loadGlyph(byte(i), g[:]); err != nil {
+			return err
+		}
+	}
+	if err := lcd.moveCursor(lcd.curCol, lcd.curRow); err != nil {
+		return err
+	}
+	lcd.activeBank = name
+	return nil
+}
+
+// Return the name of the bank last loaded with LoadBank, or "" if none
+func (lcd *I2CLCD) ActiveBank() string {
+	return lcd.activeBank
+}
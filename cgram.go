@@ -0,0 +1,162 @@
+package i2clcd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// glyphOwner records what holds a CGRAM location. Each widget kind has its
+// own owner, so a widget redrawn reuses its locations while different widgets
+// never share one.
+type glyphOwner uint8
+
+const (
+	ownerNone glyphOwner = iota
+	ownerUser
+	ownerBars
+	ownerPeak
+	ownerSparkline
+	ownerInverse
+	ownerBitmap
+	ownerLarge
+	ownerHeading
+	ownerClockHand
+	ownerKnob
+	ownerEllipsis
+	ownerUnderline
+	ownerSevenSeg
+	ownerBits
+	ownerBattery
+	ownerHeartbeat
+)
+
+// Mark location as holding a user glyph, taking it from any widget using it
+func (lcd *I2CLCD) claimUserGlyph(location byte) {
+	lcd.glyphOwners[location&0x07] = ownerUser
+}
+
+// Release a CGRAM location claimed by CreateChar so widgets may use it again.
+// The glyph stays in CGRAM until something else is loaded there.
+func (lcd *I2CLCD) FreeGlyph(location byte) {
+	if lcd.glyphOwners[location&0x07] == ownerUser {
+		lcd.glyphOwners[location&0x07] = ownerNone
+	}
+}
+
+// Return the locations held by owner, lowest first
+func (lcd *I2CLCD) glyphSlots(owner glyphOwner) []byte {
+	var slots []byte
+	for i, o := range lcd.glyphOwners {
+		if o == owner {
+			slots = append(slots, byte(i))
+		}
+	}
+	return slots
+}
+
+// Allocate n CGRAM locations to owner and return them, lowest first. An
+// owner already holding exactly n keeps them; otherwise its locations are
+// given up and n are taken from the free ones, as a contiguous run where
+// possible. Returns ErrGlyphInUse, leaving the owner's locations as they
+// were, when fewer than n are free.
+func (lcd *I2CLCD) allocGlyphs(owner glyphOwner, n int) ([]byte, error) {
+	held := lcd.glyphSlots(owner)
+	if len(held) == n {
+		return held, nil
+	}
+	lcd.releaseGlyphs(owner)
+	if n == 0 {
+		return nil, nil
+	}
+	free := lcd.glyphSlots(ownerNone)
+	if len(free) < n {
+		for _, loc := range held {
+			lcd.glyphOwners[loc] = owner
+		}
+		return nil, ErrGlyphInUse
+	}
+	slots := free[:n]
+	for i := 0; i+n <= len(free); i++ {
+		if free[i+n-1]-free[i] == byte(n-1) {
+			slots = free[i : i+n]
+			break
+		}
+	}
+	for _, loc := range slots {
+		lcd.glyphOwners[loc] = owner
+	}
+	return slots, nil
+}
+
+// Give up every location held by owner
+func (lcd *I2CLCD) releaseGlyphs(owner glyphOwner) {
+	for i, o := range lcd.glyphOwners {
+		if o == owner {
+			lcd.glyphOwners[i] = ownerNone
+		}
+	}
+}
+
+// Give up the locations held by widgets, once the screen no longer shows
+// their glyphs. User glyphs are kept.
+func (lcd *I2CLCD) releaseWidgetGlyphs() {
+	for i, o := range lcd.glyphOwners {
+		if o != ownerUser {
+			lcd.glyphOwners[i] = ownerNone
+		}
+	}
+}
+
+// Load glyphs[i] into slots[i] for each glyph
+func (lcd *I2CLCD) loadGlyphSet(slots []byte, glyphs ...[8]byte) error {
+	for i, g := range glyphs {
+		if err := lcd.loadGlyph(slots[i], g[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Program the custom character at location without claiming it, leaving the
+// controller addressing CGRAM. Rows missing from a charmap shorter than 8
+// bytes are blank.
+func (lcd *I2CLCD) loadGlyph(location byte, charmap []byte) error {
+	location &= 0x07 // We only have 8 locations 0-7
+	var glyph [8]byte
+	copy(glyph[:], charmap)
+	if err := lcd.sendCommand(LCD_SETCGRAMADDR | (location << 3)); err != nil {
+		return err
+	}
+	for _, row := range glyph {
+		if err := lcd.sendData(row); err != nil {
+			return err
+		}
+	}
+	lcd.glyphCache[location] = glyph
+	lcd.glyphsLoaded |= 1 << location
+	return nil
+}
+
+// Return Go source declaring the glyphs loaded into CGRAM since the driver
+// was created as an [8][8]byte, indexed by location. Locations never loaded
+// are left zero and marked with a comment.
+func (lcd *I2CLCD) ExportGlyphs() string {
+	var b strings.Builder
+	b.WriteString("var glyphs = [8][8]byte{\n")
+	for i, g := range lcd.glyphCache {
+		b.WriteString("\t{")
+		for j, row := range g {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "0x%02X", row)
+		}
+		fmt.Fprintf(&b, "}, // %d", i)
+		if lcd.glyphsLoaded&(1<<i) == 0 {
+			b.WriteString(", not loaded")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package i2clcd
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var userGlyph = []byte{0x0A, 0x15, 0x0A, 0x15, 0x0A, 0x15, 0x0A, 0x15}
+
+func TestWidgetsDoNotShareGlyphs(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	if err := lcd.LevelMeter(0, 50, 75); err != nil {
+		t.Fatal(err)
+	}
+	peak := lcd.glyphSlots(ownerPeak)[0]
+	peakGlyph := lcd.glyphCache[peak]
+
+	stop, err := lcd.StartHeartbeat(0, 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	// Keep the pulse from drawing while the test looks at CGRAM
+	lcd.mu.Lock()
+	defer lcd.mu.Unlock()
+	if lcd.glyphCache[peak] != peakGlyph || !lcd.barGlyphsIntact() {
+		t.Error("heartbeat overwrote the level meter's glyphs")
+	}
+
+	if err := lcd.BatteryWidget(10, 1, 60); err != nil {
+		t.Fatal(err)
+	}
+	if lcd.glyphCache[peak] != peakGlyph || !lcd.barGlyphsIntact() {
+		t.Error("battery overwrote the level meter's glyphs")
+	}
+	if len(lcd.glyphSlots(ownerHeartbeat)) != 1 {
+		t.Error("battery took the heartbeat's location")
+	}
+}
+
+func TestWidgetsAvoidUserGlyphs(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	for loc := byte(0); loc < 4; loc++ {
+		if err := lcd.CreateChar(loc, userGlyph); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lcd.ProgressBar(0, 50); err != nil {
+		t.Fatalf("ProgressBar with four free locations: %v", err)
+	}
+	for loc := byte(0); loc < 4; loc++ {
+		if lcd.glyphOwners[loc] != ownerUser || lcd.glyphCache[loc] != [8]byte(userGlyph) {
+			t.Errorf("user glyph at %d overwritten", loc)
+		}
+	}
+	if got := lcd.shadow[0][0]; got != fullBlock {
+		t.Errorf("cell 0 = %#x, want full block", got)
+	}
+	if got := lcd.shadow[0][8]; got != ' ' {
+		t.Errorf("cell 8 = %#x, want space", got)
+	}
+}
+
+func TestGlyphInUseOnlyWhenFull(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	for loc := byte(0); loc < 5; loc++ {
+		lcd.CreateChar(loc, userGlyph)
+	}
+	if err := lcd.ProgressBar(0, 50); !errors.Is(err, ErrGlyphInUse) {
+		t.Fatalf("ProgressBar with three free locations = %v, want ErrGlyphInUse", err)
+	}
+	if err := lcd.BatteryWidget(0, 1, 50); err != nil {
+		t.Fatalf("BatteryWidget with three free locations: %v", err)
+	}
+	lcd.FreeGlyph(4)
+	if err := lcd.PrintBits(0, 1, 5, 3); err != nil {
+		t.Fatalf("PrintBits after FreeGlyph: %v", err)
+	}
+}
+
+func TestClearReleasesWidgetGlyphs(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.CreateChar(7, userGlyph)
+	if err := lcd.Sparkline(0, 0, []int{1, 2, 3}, 3); !errors.Is(err, ErrGlyphInUse) {
+		t.Fatalf("Sparkline with a user glyph = %v, want ErrGlyphInUse", err)
+	}
+	lcd.FreeGlyph(7)
+	if err := lcd.Sparkline(0, 0, []int{1, 2, 3}, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := lcd.ProgressBar(1, 50); !errors.Is(err, ErrGlyphInUse) {
+		t.Fatalf("ProgressBar beside a sparkline = %v, want ErrGlyphInUse", err)
+	}
+
+	lcd.CreateChar(0, userGlyph)
+	lcd.Clear()
+	if lcd.glyphOwners[0] != ownerUser {
+		t.Error("Clear released a user glyph")
+	}
+	if err := lcd.ProgressBar(1, 50); err != nil {
+		t.Fatalf("ProgressBar after Clear: %v", err)
+	}
+}
+
+func TestWidgetsNeedingNoGlyphsReleaseTheirs(t *testing.T) {
+	blank := [][]bool{make([]bool, 5)}
+	tests := []struct {
+		name         string
+		owner        glyphOwner
+		first, again func(lcd *I2CLCD) error
+	}{
+		{"PrintInverse", ownerInverse,
+			func(lcd *I2CLCD) error { return lcd.PrintInverse(0, 0, "AB") },
+			func(lcd *I2CLCD) error { return lcd.PrintInverse(0, 0, "") }},
+		{"DrawBitmap", ownerBitmap,
+			func(lcd *I2CLCD) error { return lcd.DrawBitmap([][]bool{{true, false, true}}) },
+			func(lcd *I2CLCD) error { return lcd.DrawBitmap(blank) }},
+		{"PrintLarge", ownerLarge,
+			func(lcd *I2CLCD) error { return lcd.PrintLarge(0, 0, "A") },
+			func(lcd *I2CLCD) error { return lcd.PrintLarge(0, 0, " ") }},
+	}
+	for _, tt := range tests {
+		lcd, _ := newTestLCD(t, 16, 2)
+		if err := tt.first(lcd); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(lcd.glyphSlots(tt.owner)) == 0 {
+			t.Fatalf("%s: first call allocated no locations", tt.name)
+		}
+		if err := tt.again(lcd); err != nil {
+			t.Errorf("%s without glyphs: %v", tt.name, err)
+		}
+		if held := lcd.glyphSlots(tt.owner); len(held) != 0 {
+			t.Errorf("%s without glyphs still holds %v", tt.name, held)
+		}
+	}
+}
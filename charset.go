@@ -0,0 +1,126 @@
+package i2clcd
+
+// Charset selects how printed runes are translated to character ROM codes
+type Charset uint8
+
+const (
+	// CharsetRaw sends each rune's value as the byte code, which is correct
+	// for plain ASCII on any panel
+	CharsetRaw Charset = iota
+	// CharsetA00 targets the Japanese ROM with katakana in the upper half
+	CharsetA00
+	// CharsetA02 targets the European ROM whose upper half follows Latin-1
+	CharsetA02
+)
+
+// Code printed for runes the active charset cannot show
+const replacementChar = '?'
+
+// Characters on the A00 ROM outside the shared ASCII range
+var charsetA00 = map[rune]byte{
+	'¥': 0x5C,
+	'→': 0x7E,
+	'←': 0x7F,
+	'°': 0xDF,
+	'α': 0xE0,
+	'ä': 0xE1,
+	'β': 0xE2,
+	'ε': 0xE3,
+	'μ': 0xE4,
+	'µ': 0xE4,
+	'σ': 0xE5,
+	'ρ': 0xE6,
+	'√': 0xE8,
+	'¢': 0xEC,
+	'ñ': 0xEE,
+	'ö': 0xEF,
+	'θ': 0xF2,
+	'∞': 0xF3,
+	'Ω': 0xF4,
+	'ü': 0xF5,
+	'Σ': 0xF6,
+	'π': 0xF7,
+	'÷': 0xFD,
+	'█': 0xFF,
+}
+
+// Select the character ROM printed text is translated for
+func (lcd *I2CLCD) SetCharset(cs Charset) {
+	lcd.charset = cs
+}
+
+// Set the character ROM printed text is translated for
+func WithCharset(cs Charset) Option {
+	return func(lcd *I2CLCD) {
+		lcd.charset = cs
+	}
+}
+
+// Map a rune to the byte sent to the display under the active charset,
+// reporting whether the ROM has a glyph for it
+func (lcd *I2CLCD) lookupRune(r rune) (byte, bool) {
+	switch lcd.charset {
+	case CharsetA00:
+		// Backslash and tilde are replaced by yen and an arrow on this ROM
+		if r >= 0x20 && r <= 0x7D && r != '\\' {
+			return byte(r), true
+		}
+		// Half-width katakana and punctuation occupy 0xA1-0xDF
+		if r >= 0xFF61 && r <= 0xFF9F {
+			return byte(r - 0xFF61 + 0xA1), true
+		}
+		b, ok := charsetA00[r]
+		return b, ok
+	case CharsetA02:
+		if (r >= 0x20 && r <= 0x7E) || (r >= 0xA0 && r <= 0xFF) {
+			return byte(r), true
+		}
+		return 0, false
+	}
+	if r < 0 || r > 0xFF {
+		return 0, false
+	}
+	return byte(r), true
+}
+
+// Map a rune to the byte sent to the display. Runes the active charset has
+// no glyph for are replaced with '?'.
+func (lcd *I2CLCD) mapRune(r rune) byte {
+	if b, ok := lcd.lookupRune(r); ok {
+		return b
+	}
+	return replacementChar
+}
+
+// Report whether r has a glyph under the active charset, so it will print as
+// itself rather than as the '?' replacement. The transform is not applied.
+func (lcd *I2CLCD) CanDisplay(r rune) bool {
+	_, ok := lcd.lookupRune(r)
+	return ok
+}
+
+// Set a function applied to every printed rune before charset translation,
+// for example unicode.ToUpper. Returning a negative rune drops it. Pass nil to
+// remove the transform.
+func (lcd *I2CLCD) SetTransform(fn func(rune) rune) {
+	lcd.transform = fn
+}
+
+// Apply the transform to r, reporting false if it should be dropped
+func (lcd *I2CLCD) transformRune(r rune) (rune, bool) {
+	if lcd.transform != nil {
+		r = lcd.transform(r)
+	}
+	return r, r >= 0
+}
+
+// Translate text to display codes under the active transform and charset
+func (lcd *I2CLCD) encode(text string) []byte {
+	codes := make([]byte, 0, len(text))
+	for _, r := range text {
+		if r, ok := lcd.transformRune(r); ok {
+			codes = append(codes, lcd.mapRune(r))
+		}
+	}
+	return codes
+}
@@ -0,0 +1,19 @@
+package i2clcd
+
+import "io"
+
+// Display is the character display contract I2CLCD satisfies, for code that
+// should work with any text display driver. Positions are zero-based columns
+// and rows. Methods return an error when the display cannot be reached, and
+// SetCursor also reports positions the display rejects.
+type Display interface {
+	Clear() error
+	SetCursor(col, row uint8) error
+	Print(text string) error
+	Size() (cols, rows uint8)
+}
+
+var (
+	_ Display   = (*I2CLCD)(nil)
+	_ io.Writer = (*I2CLCD)(nil)
+)
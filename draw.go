@@ -0,0 +1,204 @@
+package i2clcd
+
+// Render a whole screen from a grid of runes, one slice per row. The grid may
+// be smaller than the display but not larger.
+func (lcd *I2CLCD) DrawGrid(grid [][]rune) error {
+	if len(grid) > int(lcd.rows) {
+		return ErrGridSize
+	}
+	for _, line := range grid {
+		if len(line) > int(lcd.cols) {
+			return ErrGridSize
+		}
+	}
+
+	for row, line := range grid {
+		if err := lcd.SetCursor(0, uint8(row)); err != nil {
+			return err
+		}
+		for _, r := range line {
+			if err := lcd.writeChar(lcd.mapRune(r)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Render a monochrome bitmap across the display, 5x8 pixels per cell, with
+// bmp[y][x] true for a lit pixel. Blank and solid cells use ROM characters;
+// every other distinct cell pattern takes a free CGRAM location, so at most 8
+// are allowed. The bitmap may be smaller than the display but not larger.
+func (lcd *I2CLCD) DrawBitmap(bmp [][]bool) error {
+	if len(bmp) > int(lcd.rows)*8 {
+		return ErrGridSize
+	}
+	width := 0
+	for _, line := range bmp {
+		if len(line) > int(lcd.cols)*5 {
+			return ErrGridSize
+		}
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+	cellRows := (len(bmp) + 7) / 8
+	cellCols := (width + 4) / 5
+
+	codes, err := lcd.loadBitmap(ownerBitmap, bmp, cellCols, cellRows)
+	if err != nil {
+		return err
+	}
+	for cy, line := range codes {
+		if err := lcd.SetCursor(0, uint8(cy)); err != nil {
+			return err
+		}
+		if err := lcd.writeChars(line...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CGRAM locations in order, for tiling before any are allocated
+var allGlyphSlots = []byte{0, 1, 2, 3, 4, 5, 6, 7}
+
+// Tile bmp like tileBitmap, allocating CGRAM locations to owner for the
+// custom glyphs and loading them. Returns ErrTooManyGlyphs when the bitmap
+// needs more locations than are free.
+func (lcd *I2CLCD) loadBitmap(owner glyphOwner, bmp [][]bool, cols, rows int) ([][]byte, error) {
+	codes, glyphs, err := tileBitmap(bmp, cols, rows, allGlyphSlots)
+	if err != nil {
+		return nil, err
+	}
+	slots, err := lcd.allocGlyphs(owner, len(glyphs))
+	if err != nil {
+		return nil, ErrTooManyGlyphs
+	}
+	for _, line := range codes {
+		for i, code := range line {
+			if int(code) < len(slots) {
+				line[i] = slots[code]
+			}
+		}
+	}
+	if err := lcd.loadGlyphSet(slots, glyphs...); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Cut bmp into cols x rows cells of 5x8 pixels, returning the character code
+// for each cell and the custom glyphs those codes refer to, glyph i going in
+// CGRAM location slots[i]. Blank and solid cells map to ROM characters;
+// identical cells share a glyph.
+func tileBitmap(bmp [][]bool, cols, rows int, slots []byte) ([][]byte, [][8]byte, error) {
+	var glyphs [][8]byte
+	codes := make([][]byte, rows)
+	for cy := range codes {
+		codes[cy] = make([]byte, cols)
+		for cx := range codes[cy] {
+			cell := bitmapCell(bmp, cx*5, cy*8)
+			code, ok := romCell(cell)
+			if !ok {
+				slot := -1
+				for i, g := range glyphs {
+					if g == cell {
+						slot = i
+						break
+					}
+				}
+				if slot < 0 {
+					if len(glyphs) == len(slots) {
+						return nil, nil, ErrTooManyGlyphs
+					}
+					slot = len(glyphs)
+					glyphs = append(glyphs, cell)
+				}
+				code = slots[slot]
+			}
+			codes[cy][cx] = code
+		}
+	}
+	return codes, glyphs, nil
+}
+
+// Extract the 5x8 cell with its top-left pixel at (x, y); pixels beyond the
+// bitmap are unlit
+func bitmapCell(bmp [][]bool, x, y int) [8]byte {
+	var cell [8]byte
+	for r := 0; r < 8 && y+r < len(bmp); r++ {
+		line := bmp[y+r]
+		for c := 0; c < 5 && x+c < len(line); c++ {
+			if line[x+c] {
+				cell[r] |= 0x10 >> c
+			}
+		}
+	}
+	return cell
+}
+
+// Return the ROM character for a blank or solid cell
+func romCell(cell [8]byte) (byte, bool) {
+	switch cell {
+	case [8]byte{}:
+		return ' ', true
+	case [8]byte{0x1F, 0x1F, 0x1F, 0x1F, 0x1F, 0x1F, 0x1F, 0x1F}:
+		return fullBlock, true
+	}
+	return 0, false
+}
+
+// Print large text spanning two rows starting at (col, row). Each character
+// is drawn from the built-in 3x5 font with every font pixel scaled to 3x3,
+// filling a 2x2 block of cells followed by a blank column, so a 16-column
+// display fits five characters. Lowercase letters are shown as uppercase.
+// Every distinct cell pattern needs a free CGRAM location, which in practice
+// limits a call to two or three characters; ErrTooManyGlyphs is returned
+// when the text needs more than are free.
+func (lcd *I2CLCD) PrintLarge(col, row uint8, text string) error {
+	// Scale the text into one bitmap, three cells wide per character
+	bmp := make([][]bool, 16)
+	for y := range bmp {
+		bmp[y] = make([]bool, len(text)*15)
+	}
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if c == ' ' {
+			continue
+		}
+		glyph, ok := font3x5[c]
+		if !ok {
+			return ErrUnsupportedChar
+		}
+		for fy, bits := range glyph {
+			for fx := 0; fx < 3; fx++ {
+				if bits&(0x4>>fx) == 0 {
+					continue
+				}
+				for y := fy * 3; y < fy*3+3; y++ {
+					for x := fx * 3; x < fx*3+3; x++ {
+						bmp[y][i*15+x] = true
+					}
+				}
+			}
+		}
+	}
+
+	codes, err := lcd.loadBitmap(ownerLarge, bmp, len(text)*3, 2)
+	if err != nil {
+		return err
+	}
+	for cy, line := range codes {
+		if err := lcd.SetCursor(col, row+uint8(cy)); err != nil {
+			return err
+		}
+		if err := lcd.writeChars(line...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package i2clcd
+
+// A 5x7 font for the characters the glyph-composing helpers can render.
+// Each row holds five pixels with the leftmost in bit 4.
+var font5x7 = map[byte][7]byte{
+	' ': {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	'!': {0x04, 0x04, 0x04, 0x04, 0x04, 0x00, 0x04},
+	'-': {0x00, 0x00, 0x00, 0x1F, 0x00, 0x00, 0x00},
+	'.': {0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C},
+	'0': {0x0E, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0E},
+	'1': {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'2': {0x0E, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1F},
+	'3': {0x1F, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0E},
+	'4': {0x02, 0x06, 0x0A, 0x12, 0x1F, 0x02, 0x02},
+	'5': {0x1F, 0x10, 0x1E, 0x01, 0x01, 0x11, 0x0E},
+	'6': {0x06, 0x08, 0x10, 0x1E, 0x11, 0x11, 0x0E},
+	'7': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0E, 0x11, 0x11, 0x0E, 0x11, 0x11, 0x0E},
+	'9': {0x0E, 0x11, 0x11, 0x0F, 0x01, 0x02, 0x0C},
+	':': {0x00, 0x0C, 0x0C, 0x00, 0x0C, 0x0C, 0x00},
+	'?': {0x0E, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+	'A': {0x0E, 0x11, 0x11, 0x11, 0x1F, 0x11, 0x11},
+	'B': {0x1E, 0x11, 0x11, 0x1E, 0x11, 0x11, 0x1E},
+	'C': {0x0E, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0E},
+	'D': {0x1C, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1C},
+	'E': {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x1F},
+	'F': {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x10},
+	'G': {0x0E, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0F},
+	'H': {0x11, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'I': {0x0E, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'J': {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0C},
+	'K': {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L': {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1F},
+	'M': {0x11, 0x1B, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N': {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O': {0x0E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'P': {0x1E, 0x11, 0x11, 0x1E, 0x10, 0x10, 0x10},
+	'Q': {0x0E, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0D},
+	'R': {0x1E, 0x11, 0x11, 0x1E, 0x14, 0x12, 0x11},
+	'S': {0x0F, 0x10, 0x10, 0x0E, 0x01, 0x01, 0x1E},
+	'T': {0x1F, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U': {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'V': {0x11, 0x11, 0x11, 0x11, 0x11, 0x0A, 0x04},
+	'W': {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0A},
+	'X': {0x11, 0x11, 0x0A, 0x04, 0x0A, 0x11, 0x11},
+	'Y': {0x11, 0x11, 0x11, 0x0A, 0x04, 0x04, 0x04},
+	'Z': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1F},
+}
+
+// A 3x5 font for PrintLarge. Each row holds three pixels with the leftmost
+// in bit 2.
+var font3x5 = map[byte][5]byte{
+	'0': {0x7, 0x5, 0x5, 0x5, 0x7},
+	'1': {0x2, 0x6, 0x2, 0x2, 0x7},
+	'2': {0x7, 0x1, 0x7, 0x4, 0x7},
+	'3': {0x7, 0x1, 0x3, 0x1, 0x7},
+	'4': {0x5, 0x5, 0x7, 0x1, 0x1},
+	'5': {0x7, 0x4, 0x7, 0x1, 0x7},
+	'6': {0x7, 0x4, 0x7, 0x5, 0x7},
+	'7': {0x7, 0x1, 0x2, 0x2, 0x2},
+	'8': {0x7, 0x5, 0x7, 0x5, 0x7},
+	'9': {0x7, 0x5, 0x7, 0x1, 0x7},
+	'A': {0x2, 0x5, 0x7, 0x5, 0x5},
+	'B': {0x6, 0x5, 0x6, 0x5, 0x6},
+	'C': {0x3, 0x4, 0x4, 0x4, 0x3},
+	'D': {0x6, 0x5, 0x5, 0x5, 0x6},
+	'E': {0x7, 0x4, 0x6, 0x4, 0x7},
+	'F': {0x7, 0x4, 0x6, 0x4, 0x4},
+	'G': {0x3, 0x4, 0x5, 0x5, 0x3},
+	'H': {0x5, 0x5, 0x7, 0x5, 0x5},
+	'I': {0x7, 0x2, 0x2, 0x2, 0x7},
+	'J': {0x1, 0x1, 0x1, 0x5, 0x2},
+	'K': {0x5, 0x5, 0x6, 0x5, 0x5},
+	'L': {0x4, 0x4, 0x4, 0x4, 0x7},
+	'M': {0x5, 0x7, 0x7, 0x5, 0x5},
+	'N': {0x6, 0x5, 0x5, 0x5, 0x5},
+	'O': {0x2, 0x5, 0x5, 0x5, 0x2},
+	'P': {0x6, 0x5, 0x6, 0x4, 0x4},
+	'Q': {0x2, 0x5, 0x5, 0x6, 0x3},
+	'R': {0x6, 0x5, 0x6, 0x5, 0x5},
+	'S': {0x3, 0x4, 0x2, 0x1, 0x6},
+	'T': {0x7, 0x2, 0x2, 0x2, 0x2},
+	'U': {0x5, 0x5, 0x5, 0x5, 0x7},
+	'V': {0x5, 0x5, 0x5, 0x5, 0x2},
+	'W': {0x5, 0x5, 0x7, 0x7, 0x5},
+	'X': {0x5, 0x5, 0x2, 0x5, 0x5},
+	'Y': {0x5, 0x5, 0x2, 0x2, 0x2},
+	'Z': {0x7, 0x1, 0x2, 0x4, 0x7},
+}
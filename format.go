@@ -0,0 +1,196 @@
+package i2clcd
+
+import (
+	"strconv"
+	"time"
+)
+
+// ROM code for the degree sign on all but the European ROM
+const degreeSymbol = 0xDF
+
+// Print an integer with sep between each group of three digits, e.g. 1,234,567.
+// A zero sep defaults to ','.
+func (lcd *I2CLCD) PrintGrouped(n int64, sep byte) {
+	if sep == 0 {
+		sep = ','
+	}
+	lcd.Print(groupDigits(strconv.FormatInt(n, 10), sep))
+}
+
+// Print n at the cursor in the given base, from 2 to 36, using lowercase
+// letters for digits above 9. Other bases return ErrInvalidBase.
+func (lcd *I2CLCD) PrintBase(n int64, base int) error {
+	if base < 2 || base > 36 {
+		return ErrInvalidBase
+	}
+	return lcd.Print(strconv.FormatInt(n, base))
+}
+
+// Insert sep every three digits from the right, keeping any leading sign
+func groupDigits(s string, sep byte) string {
+	sign := ""
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	if len(s) <= 3 {
+		return sign + s
+	}
+
+	out := make([]byte, 0, len(s)+len(s)/3)
+	lead := len(s) % 3
+	if lead == 0 {
+		lead = 3
+	}
+	out = append(out, s[:lead]...)
+	for i := lead; i < len(s); i += 3 {
+		out = append(out, sep)
+		out = append(out, s[i:i+3]...)
+	}
+	return sign + string(out)
+}
+
+// Return the degree sign's code on the active character ROM
+func (lcd *I2CLCD) degreeCode() byte {
+	if lcd.charset == CharsetA02 {
+		return lcd.mapRune('°')
+	}
+	return degreeSymbol
+}
+
+// Print a temperature at (col, row) with one decimal place and a degree sign.
+// The value is given in Celsius; a unit of 'F' or 'f' converts it to Fahrenheit.
+func (lcd *I2CLCD) PrintTemperature(col, row uint8, celsius float64, unit rune) {
+	value, suffix := celsius, byte('C')
+	if unit == 'F' || unit == 'f' {
+		value, suffix = celsius*9/5+32, 'F'
+	}
+
+	lcd.SetCursor(col, row)
+	lcd.Print(strconv.FormatFloat(value, 'f', 1, 64))
+	lcd.writeChar(lcd.degreeCode())
+	lcd.writeChar(suffix)
+}
+
+// Print a duration at (col, row) as mm:ss, or hh:mm:ss once it reaches an
+// hour. Every field is zero-padded so a ticking value always covers the same
+// cells. Negative durations print as zero.
+func (lcd *I2CLCD) PrintDuration(col, row uint8, d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	secs := int64(d / time.Second)
+	h, m, s := secs/3600, secs/60%60, secs%60
+
+	buf := make([]byte, 0, 8)
+	if h > 0 {
+		buf = appendPadded(buf, h, 2)
+		buf = append(buf, ':')
+	}
+	buf = appendPadded(buf, m, 2)
+	buf = append(buf, ':')
+	buf = appendPadded(buf, s, 2)
+
+	lcd.SetCursor(col, row)
+	lcd.Print(string(buf))
+}
+
+// Append n in decimal, left-padded with zeros to at least width digits
+func appendPadded(buf []byte, n int64, width int) []byte {
+	digits := strconv.FormatInt(n, 10)
+	for i := len(digits); i < width; i++ {
+		buf = append(buf, '0')
+	}
+	return append(buf, digits...)
+}
+
+// Print trueStr or falseStr depending on b, padded to the longer of the two
+// so the field keeps the same width when toggled. When both strings are
+// empty they default to "ON" and "OFF".
+func (lcd *I2CLCD) PrintBool(b bool, trueStr, falseStr string) {
+	if trueStr == "" && falseStr == "" {
+		trueStr, falseStr = "ON", "OFF"
+	}
+	width := cellCount(trueStr)
+	if cellCount(falseStr) > width {
+		width = cellCount(falseStr)
+	}
+
+	text := falseStr
+	if b {
+		text = trueStr
+	}
+	lcd.Print(fitText(text, width))
+}
+
+// Print value at (col, row) as a sign in the first cell followed by the
+// magnitude right-aligned in the next fieldWidth cells, e.g. "+  3" or "- 12"
+// for a width of 3. Zero is shown with a plus sign. The sign stays in the same
+// column whatever the value; magnitudes wider than the field are printed whole.
+func (lcd *I2CLCD) PrintSigned(col, row uint8, value int, fieldWidth uint8) {
+	sign := "+"
+	magnitude := strconv.FormatUint(uint64(value), 10)
+	if value < 0 {
+		sign = "-"
+		magnitude = strconv.FormatUint(-uint64(value), 10)
+	}
+	lcd.SetCursor(col, row)
+	lcd.Print(sign + spaces(int(fieldWidth)-len(magnitude)) + magnitude)
+}
+
+// Print n at the cursor padded with leading zeros to width digits, e.g.
+// 00042. A value too large for the field is shown as all nines, and a
+// negative value has its minus sign in the first cell.
+func (lcd *I2CLCD) PrintZeroPadded(n int, width uint8) {
+	var buf []byte
+	digits := int(width)
+	magnitude := uint64(n)
+	if n < 0 {
+		buf = append(buf, '-')
+		digits--
+		magnitude = -uint64(n)
+	}
+	if digits <= 0 {
+		lcd.Print(string(buf))
+		return
+	}
+
+	text := strconv.FormatUint(magnitude, 10)
+	if len(text) > digits {
+		for i := 0; i < digits; i++ {
+			buf = append(buf, '9')
+		}
+		lcd.Print(string(buf))
+		return
+	}
+	for i := len(text); i < digits; i++ {
+		buf = append(buf, '0')
+	}
+	lcd.Print(string(append(buf, text...)))
+}
+
+// Print n at the cursor abbreviated with a k, M, G, T, P or E suffix and one
+// decimal place, e.g. 1500 as "1.5k". Values are truncated rather than
+// rounded, so 999999 shows as "999.9k"; magnitudes below 1000 are printed in
+// full.
+func (lcd *I2CLCD) PrintHumanized(n int64) {
+	sign := ""
+	magnitude := uint64(n)
+	if n < 0 {
+		sign = "-"
+		magnitude = -uint64(n)
+	}
+	if magnitude < 1000 {
+		lcd.Print(sign + strconv.FormatUint(magnitude, 10))
+		return
+	}
+
+	unit := uint64(1000)
+	suffixes := "kMGTPE"
+	i := 0
+	for magnitude/unit >= 1000 && i < len(suffixes)-1 {
+		unit *= 1000
+		i++
+	}
+	tenths := magnitude / (unit / 10)
+	lcd.Print(sign + strconv.FormatUint(tenths/10, 10) + "." + strconv.FormatUint(tenths%10, 10) + suffixes[i:i+1])
+}
@@ -0,0 +1,12 @@
+package i2clcd
+
+import "testing"
+
+func TestPrintBoolWidthInCells(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.SetCharset(CharsetA00)
+	lcd.PrintBool(false, "°°°°", "OFF")
+	if lcd.curCol != 4 {
+		t.Errorf("PrintBool took %d cells, want 4", lcd.curCol)
+	}
+}
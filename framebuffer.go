@@ -0,0 +1,231 @@
+package i2clcd
+
+import "time"
+
+// Allocate a rows x cols screen buffer filled with spaces
+func newScreen(cols, rows uint8) [][]byte {
+	screen := make([][]byte, rows)
+	for i := range screen {
+		screen[i] = make([]byte, cols)
+		for j := range screen[i] {
+			screen[i][j] = ' '
+		}
+	}
+	return screen
+}
+
+// Reset both buffers, and the terminal row buffer if in use, to match a
+// freshly cleared display
+func (lcd *I2CLCD) blankFramebuffer() {
+	for row := range lcd.shadow {
+		for col := range lcd.shadow[row] {
+			lcd.shadow[row][col] = ' '
+			lcd.frame[row][col] = ' '
+		}
+	}
+	for _, line := range lcd.lines {
+		for col := range line {
+			line[col] = ' '
+		}
+	}
+}
+
+// Write line to row starting at column 0
+func (lcd *I2CLCD) paintRow(row uint8, line []byte) {
+	lcd.SetCursor(0, row)
+	for _, b := range line {
+		lcd.writeChar(b)
+	}
+}
+
+// Return what the driver believes is on the glass, one line per row separated
+// by newlines. Cells hold raw character codes, so custom characters appear as
+// bytes 0-7.
+func (lcd *I2CLCD) Dump() string {
+	buf := make([]byte, 0, (int(lcd.cols)+1)*int(lcd.rows))
+	for row, line := range lcd.shadow {
+		if row > 0 {
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, line...)
+	}
+	return string(buf)
+}
+
+// Queue a character for (col, row) without touching the bus. Queued cells are
+// sent by the next Flush. Positions outside the display are ignored.
+func (lcd *I2CLCD) SetChar(col, row uint8, b byte) {
+	if row >= lcd.rows || col >= lcd.cols {
+		return
+	}
+	lcd.frame[row][col] = b
+}
+
+// Queue every cell of grid with SetChar, mapping runes through the active
+// charset. Cells outside the display are ignored.
+func (lcd *I2CLCD) queueGrid(grid [][]rune) {
+	for row, line := range grid {
+		if row >= int(lcd.rows) {
+			break
+		}
+		for col, r := range line {
+			if col >= int(lcd.cols) {
+				break
+			}
+			lcd.SetChar(uint8(col), uint8(row), lcd.mapRune(r))
+		}
+	}
+}
+
+// Send every cell queued with SetChar that differs from what is displayed,
+// then return the cursor to where it was. The writes are batched into Tx
+// calls of at most the SetMaxTxChunk size. Stops at the first failed chunk
+// and returns its error.
+func (lcd *I2CLCD) Flush() error {
+	lcd.beginBatch()
+	err := lcd.flushFrame()
+	if endErr := lcd.endBatch(); err == nil {
+		err = endErr
+	}
+	return err
+}
+
+// Write the changed cells for Flush while batching
+func (lcd *I2CLCD) flushFrame() error {
+	prevCol, prevRow := lcd.curCol, lcd.curRow
+	moved := false
+	for row := range lcd.frame {
+		for col, b := range lcd.frame[row] {
+			if lcd.shadow[row][col] == b {
+				continue
+			}
+			if !moved || lcd.curRow != uint8(row) || lcd.curCol != uint8(col) {
+				if err := lcd.SetCursor(uint8(col), uint8(row)); err != nil {
+					return err
+				}
+				moved = true
+			}
+			if err := lcd.writeChar(b); err != nil {
+				return err
+			}
+		}
+	}
+	if moved {
+		return lcd.moveCursor(prevCol, prevRow)
+	}
+	return nil
+}
+
+// Show text centered on the last row for d, then put back what was there
+func (lcd *I2CLCD) Toast(text string, d time.Duration) {
+	if lcd.rows == 0 {
+		return
+	}
+	row := lcd.rows - 1
+	saved := append([]byte(nil), lcd.shadow[row]...)
+	prevCol, prevRow := lcd.curCol, lcd.curRow
+
+	lcd.PrintCentered(row, text)
+	lcd.sleep(d)
+
+	lcd.paintRow(row, saved)
+	lcd.moveCursor(prevCol, prevRow)
+}
+
+// Flush cells queued with SetChar in the background at most maxFPS times a
+// second, so rapid updates coalesce into one burst per frame. Returns a
+// function that stops the loop.
+func (lcd *I2CLCD) StartAutoFlush(maxFPS int) (stop func()) {
+	if maxFPS < 1 {
+		maxFPS = 1
+	}
+	interval := time.Second / time.Duration(maxFPS)
+	return lcd.animate(func(frame int) time.Duration {
+		lcd.Flush()
+		return interval
+	})
+}
+
+// Re-run the initialization sequence, for example after a bus glitch, then
+// put back the display, cursor, blink, backlight and entry mode settings, the
+// active glyph bank, the last known screen contents and the cursor position,
+// so the reset is invisible to the user. Call Clear afterwards for a blank
+// screen instead. Returns the first bus error.
+func (lcd *I2CLCD) Reset() error {
+	saved := make([][]byte, len(lcd.shadow))
+	for row := range lcd.shadow {
+		saved[row] = append([]byte(nil), lcd.shadow[row]...)
+	}
+	prevCol, prevRow := lcd.curCol, lcd.curRow
+	display, cursor, blink := lcd.display, lcd.cursor, lcd.blink
+	backlight, entryMode := lcd.BacklightOn(), lcd.entryMode
+	// The restored screen still shows the widgets' glyphs
+	owners := lcd.glyphOwners
+
+	if err := lcd.Init(); err != nil {
+		return err
+	}
+	lcd.glyphOwners = owners
+	lcd.display, lcd.cursor, lcd.blink = display, cursor, blink
+	if err := lcd.updateDisplayControl(); err != nil {
+		return err
+	}
+	if lcd.activeBank != "" {
+		if err := lcd.LoadBank(lcd.activeBank); err != nil {
+			return err
+		}
+	}
+	// Repaint left to right before restoring the entry mode
+	for row, line := range saved {
+		lcd.paintRow(uint8(row), line)
+	}
+	lcd.entryMode = entryMode
+	if err := lcd.sendCommand(LCD_ENTRYMODESET | lcd.entryMode); err != nil {
+		return err
+	}
+	if err := lcd.moveCursor(prevCol, prevRow); err != nil {
+		return err
+	}
+	lcd.setBacklight(backlight)
+	return lcd.Err()
+}
+
+// Start a transaction: until Commit, cursor moves and printed characters only
+// update the framebuffer instead of being sent. Other commands such as Clear
+// still take effect immediately.
+func (lcd *I2CLCD) Begin() {
+	lcd.inTx = true
+}
+
+// End a transaction started with Begin, sending every changed cell in one
+// pass and leaving the cursor where the transaction left it. Returns the
+// first bus error.
+func (lcd *I2CLCD) Commit() error {
+	return lcd.SwapBuffers()
+}
+
+// Turn double buffering on or off. While on, cursor moves and printed
+// characters only update the back buffer, as inside Begin, and nothing
+// reaches the display until SwapBuffers. Turning it off swaps any pending
+// changes onto the display.
+func (lcd *I2CLCD) SetDoubleBuffered(on bool) {
+	lcd.doubleBuffered = on
+	if on {
+		lcd.inTx = true
+		return
+	}
+	lcd.SwapBuffers()
+}
+
+// Send every cell that differs between the back buffer and the display in a
+// single batched burst, leaving the cursor where the buffered writes left it.
+// Also ends a transaction started with Begin. Returns the first bus error.
+func (lcd *I2CLCD) SwapBuffers() error {
+	lcd.inTx = false
+	err := lcd.Flush()
+	if err == nil {
+		err = lcd.moveCursor(lcd.curCol, lcd.curRow)
+	}
+	lcd.inTx = lcd.doubleBuffered
+	return err
+}
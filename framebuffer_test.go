@@ -0,0 +1,24 @@
+package i2clcd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCommitReturnsFailedChunk(t *testing.T) {
+	lcd, bus := newTestLCD(t, 16, 2)
+	lcd.SetMaxTxChunk(8)
+	lcd.Begin()
+	lcd.PrintAt(0, 0, "hello world")
+	bus.mu.Lock()
+	bus.failOver = 1
+	bus.mu.Unlock()
+	if err := lcd.Commit(); !errors.Is(err, errBusDown) {
+		t.Errorf("Commit with a failing chunk = %v, want errBusDown", err)
+	}
+
+	lcd.SetChar(0, 1, 'x')
+	if err := lcd.Flush(); !errors.Is(err, errBusDown) {
+		t.Errorf("Flush with a failing chunk = %v, want errBusDown", err)
+	}
+}
@@ -0,0 +1,60 @@
+package i2clcd
+
+import "sync"
+
+// Group manages several displays that share one I2C bus. A group operation
+// visits the displays one at a time, waiting for each display's animation
+// frame in progress, so group operations never interleave with each other.
+// Each display keeps its own locks, though, so an animation or direct call on
+// one display can still interleave its transfers with another display's;
+// the bus must serialize individual Tx calls.
+type Group struct {
+	mu       sync.Mutex
+	displays []*I2CLCD
+}
+
+// Add a display to the group
+func (g *Group) Add(lcd *I2CLCD) {
+	g.mu.Lock()
+	g.displays = append(g.displays, lcd)
+	g.mu.Unlock()
+}
+
+// Return the number of displays in the group
+func (g *Group) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.displays)
+}
+
+// Return the display at index i, in the order they were added
+func (g *Group) At(i int) *I2CLCD {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.displays[i]
+}
+
+// Clear every display in the group
+func (g *Group) ClearAll() {
+	g.each(func(lcd *I2CLCD) {
+		lcd.Clear()
+	})
+}
+
+// Print text at the current cursor of every display in the group
+func (g *Group) PrintAll(text string) {
+	g.each(func(lcd *I2CLCD) {
+		lcd.Print(text)
+	})
+}
+
+// Run fn on each display in turn while holding the group and display locks
+func (g *Group) each(fn func(lcd *I2CLCD)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, lcd := range g.displays {
+		lcd.mu.Lock()
+		fn(lcd)
+		lcd.mu.Unlock()
+	}
+}
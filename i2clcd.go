@@ -2,12 +2,20 @@
 package i2clcd
 
 import (
+	"errors"
 	"machine"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
+// i2cBus is the part of machine.I2C the driver uses
+type i2cBus interface {
+	Tx(addr uint16, w, r []byte) error
+}
+
 type I2CLCD struct {
-	bus       *machine.I2C
+	bus       i2cBus
 	addr      uint8
 	cols      uint8
 	rows      uint8
@@ -15,6 +23,138 @@ type I2CLCD struct {
 	display   bool
 	cursor    bool
 	blink     bool
+	curCol    uint8
+	curRow    uint8
+	savedCol  uint8
+	savedRow  uint8
+
+	// Sends after Init that use warmupDelay, and how many remain
+	warmupSends int
+	warmupLeft  int
+	warmupDelay time.Duration
+
+	// Entry-mode bits last sent: text direction and autoscroll
+	entryMode byte
+
+	// Columns the display has been shifted right by the scroll commands,
+	// modulo the 40-column DDRAM line
+	shift int8
+
+	spinnerFrame uint8
+
+	sleep func(time.Duration)
+
+	wrap  WrapMode
+	lines [][]byte
+
+	pins               PinMap
+	enableDelay        time.Duration
+	commandDelay       time.Duration
+	backlightActiveLow bool
+	inputPins          byte
+
+	// Held by background animations while they draw a frame
+	mu sync.Mutex
+
+	// Held for every expander transfer, and guards the backlight, batch and
+	// idle-timer state shared with the idle timer's goroutine
+	busMu sync.Mutex
+
+	// shadow mirrors what is on the glass; frame holds content queued with
+	// SetChar until the next Flush
+	shadow [][]byte
+	frame  [][]byte
+
+	idleTimeout time.Duration
+	idleTimer   *time.Timer
+	lastSend    time.Time
+	// Set while the backlight is off because the idle timer turned it off
+	idleDimmed bool
+
+	err error
+
+	doublePulse bool
+
+	trace func(value byte, isData bool)
+
+	strict bool
+
+	charset Charset
+
+	// Single-row display whose right half lives in the second DDRAM line
+	split bool
+
+	// Explicit function-set line mode, overriding the one derived from rows
+	lineModeSet bool
+	twoLine     bool
+
+	// Set between Begin and Commit, or while double buffered, when output
+	// goes to the framebuffer
+	inTx           bool
+	doubleBuffered bool
+
+	blankOnOff bool
+
+	// Send control characters verbatim instead of interpreting them
+	rawControl bool
+
+	// Start of a UTF-8 sequence left incomplete by the last Write
+	writeTail []byte
+
+	transform func(rune) rune
+
+	// Expander bytes collected while batching, sent at most maxTxChunk at a
+	// time
+	batching   bool
+	batch      []byte
+	maxTxChunk int
+
+	// What holds each CGRAM location
+	glyphOwners [8]glyphOwner
+
+	// Last glyph loaded into each CGRAM location, and which have been loaded
+	glyphCache   [8][8]byte
+	glyphsLoaded uint8
+
+	// Set while barSlots hold the bar glyphs
+	barGlyphsLoaded bool
+	barSlots        [4]byte
+
+	// Named glyph sets and the one currently in CGRAM
+	banks      map[string][][8]byte
+	activeBank string
+}
+
+// PinMap describes which PCF8574 output bit drives each control line. The
+// four data lines D4-D7 are always wired to P4-P7. The EN mask is strobed by
+// every nibble write and the RS mask is set for data bytes; RW is kept low
+// since the driver never reads from the controller.
+type PinMap struct {
+	RS        byte
+	RW        byte
+	EN        byte
+	Backlight byte
+}
+
+// Report whether every line maps to its own single bit among P0-P3, which
+// is required for the driver to strobe EN and select RS correctly
+func (p PinMap) Valid() bool {
+	var used byte
+	for _, mask := range []byte{p.RS, p.RW, p.EN, p.Backlight} {
+		if mask == 0 || mask&(mask-1) != 0 || mask&0xF0 != 0 || used&mask != 0 {
+			return false
+		}
+		used |= mask
+	}
+	return true
+}
+
+// DefaultPinMap is the wiring used by the common PCF8574 backpacks
+var DefaultPinMap = PinMap{
+	RS:        0x01,
+	RW:        0x02,
+	EN:        0x04,
+	Backlight: LCD_BACKLIGHT,
 }
 
 const (
@@ -53,201 +193,1065 @@ const (
 	LCD_SCROLLRIGHT = 0x1C
 )
 
+var (
+	// ErrGridSize is returned when a grid is larger than the display
+	ErrGridSize = errors.New("i2clcd: grid exceeds display size")
+
+	// ErrInvalidLocation is returned for a CGRAM location outside 0-7
+	ErrInvalidLocation = errors.New("i2clcd: CGRAM location must be 0-7")
+
+	// ErrTooManyGlyphs is returned when an operation needs more than the 8
+	// available CGRAM locations
+	ErrTooManyGlyphs = errors.New("i2clcd: more than 8 custom glyphs needed")
+
+	// ErrUnsupportedChar is returned when a character has no font glyph
+	ErrUnsupportedChar = errors.New("i2clcd: character not in font")
+
+	// ErrOutOfRange is returned in strict mode when a position or text
+	// would otherwise be clamped or truncated to fit the display
+	ErrOutOfRange = errors.New("i2clcd: position or text outside display")
+
+	// ErrGlyphInUse is returned by widgets when too few CGRAM locations are
+	// free of user glyphs and other widgets' glyphs
+	ErrGlyphInUse = errors.New("i2clcd: not enough free CGRAM locations")
+
+	// ErrInvalidBase is returned for a number base outside 2-36
+	ErrInvalidBase = errors.New("i2clcd: base must be 2-36")
+
+	// ErrUnknownBank is returned when loading a glyph bank that was never
+	// defined
+	ErrUnknownBank = errors.New("i2clcd: glyph bank not defined")
+
+	// ErrNoDevice is returned by Detect when no candidate address
+	// acknowledges
+	ErrNoDevice = errors.New("i2clcd: no device acknowledged")
+)
+
 // Create a new I2CLCD instance
 func NewI2CLCD(bus *machine.I2C, addr, cols, rows uint8) *I2CLCD {
-	return &I2CLCD{
-		bus:       bus,
-		addr:      addr,
-		cols:      cols,
-		rows:      rows,
-		backlight: true,
-		display:   true,
-		cursor:    false,
-		blink:     false,
+	return New(bus, WithAddress(addr), WithSize(cols, rows))
+}
+
+// Block until no background animation is part way through drawing a frame
+func (lcd *I2CLCD) Sync() {
+	lcd.mu.Lock()
+	lcd.mu.Unlock()
+}
+
+// Return the configured display geometry
+func (lcd *I2CLCD) Size() (cols, rows uint8) {
+	return lcd.cols, lcd.rows
+}
+
+// Report whether the backlight is on
+func (lcd *I2CLCD) BacklightOn() bool {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	return lcd.backlight
+}
+
+// Report whether the display is on
+func (lcd *I2CLCD) DisplayEnabled() bool {
+	return lcd.display
+}
+
+// Report whether the cursor underline is shown
+func (lcd *I2CLCD) CursorEnabled() bool {
+	return lcd.cursor
+}
+
+// Report whether the cursor cell blinks
+func (lcd *I2CLCD) BlinkEnabled() bool {
+	return lcd.blink
+}
+
+// Return how many characters fit from col to the end of a row, 0 if col is
+// past the last column
+func (lcd *I2CLCD) MaxLenAt(col uint8) uint8 {
+	if col >= lcd.cols {
+		return 0
 	}
+	return lcd.cols - col
+}
+
+// Return how many characters fit from the cursor to the end of its row
+func (lcd *I2CLCD) ColsRemaining() uint8 {
+	return lcd.MaxLenAt(lcd.curCol)
+}
+
+// Help identify an unknown panel by writing marker text where the third and
+// fourth lines of a 20x4 display start, then asking confirm whether they are
+// visible. The geometry is set to 20x4 if confirm reports true and 16x2
+// otherwise, and the display is cleared. Call after Init.
+func (lcd *I2CLCD) DetectGeometry(confirm func() (sawBottomRows bool)) (cols, rows uint8) {
+	lcd.Clear()
+	for i := uint8(0); i < 4; i++ {
+		lcd.sendCommand(LCD_SETDDRAMADDR | rowOffset(i, 20))
+		for _, b := range []byte("ROW ") {
+			lcd.sendData(b)
+		}
+		lcd.sendData('1' + i)
+	}
+
+	if confirm() {
+		lcd.setGeometry(20, 4)
+	} else {
+		lcd.setGeometry(16, 2)
+	}
+	lcd.Clear()
+	return lcd.cols, lcd.rows
+}
+
+// Change the display geometry and reallocate the framebuffer and, in
+// WrapScroll mode, the terminal row buffer to match, homing the cursor
+func (lcd *I2CLCD) setGeometry(cols, rows uint8) {
+	lcd.cols, lcd.rows = cols, rows
+	lcd.shadow = newScreen(cols, rows)
+	lcd.frame = newScreen(cols, rows)
+	if lcd.wrap == WrapScroll {
+		lcd.lines = newScreen(cols, rows)
+	}
+	lcd.curCol, lcd.curRow = 0, 0
+}
+
+// Report whether the display was configured with zero rows or columns, in
+// which case size-dependent operations do nothing
+func (lcd *I2CLCD) noArea() bool {
+	return lcd.cols == 0 || lcd.rows == 0
+}
+
+// Run fn with the enable pulse and post-nibble delays temporarily set to
+// enable and command, restoring the previous timing afterwards. Use it to
+// slow down just the operation a panel is fussy about.
+func (lcd *I2CLCD) WithTiming(enable, command time.Duration, fn func()) {
+	enableDelay, commandDelay := lcd.enableDelay, lcd.commandDelay
+	lcd.enableDelay, lcd.commandDelay = enable, command
+	defer func() {
+		lcd.enableDelay, lcd.commandDelay = enableDelay, commandDelay
+	}()
+	fn()
+}
+
+// Replace the function used for all internal delays. Passing a no-op lets
+// host-side tests run the full command sequence at speed; nil restores
+// time.Sleep.
+func (lcd *I2CLCD) SetClock(sleep func(time.Duration)) {
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	lcd.sleep = sleep
 }
 
 // Send a command to the LCD
-func (lcd *I2CLCD) sendCommand(cmd byte) {
-	lcd.send(cmd, 0)
+func (lcd *I2CLCD) sendCommand(cmd byte) error {
+	return lcd.send(cmd, 0)
 }
 
 // Send data to the LCD
-func (lcd *I2CLCD) sendData(data byte) {
-	lcd.send(data, 1)
+func (lcd *I2CLCD) sendData(data byte) error {
+	return lcd.send(data, lcd.pins.RS)
 }
 
 // Send a byte to the LCD
-func (lcd *I2CLCD) send(value byte, mode byte) {
-	highNibble := value & 0xF0
-	lowNibble := (value << 4) & 0xF0
-	lcd.write4Bits(highNibble | mode)
-	lcd.write4Bits(lowNibble | mode)
+func (lcd *I2CLCD) send(value byte, mode byte) error {
+	if lcd.trace != nil {
+		lcd.trace(value, mode != 0)
+	}
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	if lcd.idleTimer != nil {
+		lcd.lastSend = time.Now()
+		if lcd.idleDimmed {
+			// Undo only the idle timer's dimming, not an explicit NoBacklight
+			lcd.idleDimmed = false
+			lcd.backlight = true
+		}
+		lcd.idleTimer.Reset(lcd.idleTimeout)
+	}
+	if lcd.warmupLeft > 0 {
+		// Still warming up after Init; use the slow delays for this byte
+		lcd.warmupLeft--
+		enableDelay, commandDelay := lcd.enableDelay, lcd.commandDelay
+		lcd.enableDelay, lcd.commandDelay = lcd.warmupDelay, lcd.warmupDelay
+		defer func() {
+			lcd.enableDelay, lcd.commandDelay = enableDelay, commandDelay
+		}()
+	}
+	// busMu stays held across both nibbles, so a backlight change from
+	// another goroutine waits until the whole byte has been sent
+	frames := encodeFrames(value, mode, lcd.backlight, lcd.backlightActiveLow, lcd.pins)
+	err := lcd.writeNibble(frames[:3])
+	if err == nil {
+		err = lcd.writeNibble(frames[3:])
+	}
+	return err
 }
 
-// Write 4 bits to the LCD
-func (lcd *I2CLCD) write4Bits(value byte) {
-	lcd.expanderWrite(value)
-	lcd.pulseEnable(value)
+// Call fn with every command and data byte sent to the display. Pass nil to
+// stop tracing.
+func (lcd *I2CLCD) SetTrace(fn func(value byte, isData bool)) {
+	lcd.trace = fn
 }
 
-// Write a byte to the I2C expander
-func (lcd *I2CLCD) expanderWrite(data byte) {
-	backlight := byte(0x00)
-	if lcd.backlight {
-		backlight = LCD_BACKLIGHT
+// Write a character at the cursor and advance the tracked column. Writes
+// past the end of the row are dropped, since the controller would place them
+// in another row's DDRAM.
+func (lcd *I2CLCD) writeChar(b byte) error {
+	if lcd.noArea() || lcd.curCol >= lcd.cols {
+		return nil
+	}
+	if lcd.inTx {
+		lcd.SetChar(lcd.curCol, lcd.curRow, b)
+		lcd.curCol++
+		return nil
+	}
+	if err := lcd.sendData(b); err != nil {
+		return err
+	}
+	if lcd.curRow < lcd.rows && lcd.curCol < lcd.cols {
+		lcd.shadow[lcd.curRow][lcd.curCol] = b
+		lcd.frame[lcd.curRow][lcd.curCol] = b
+	}
+	lcd.curCol++
+	if lcd.split && lcd.curCol == lcd.cols/2 {
+		// The address counter does not cross banks by itself
+		return lcd.sendCommand(LCD_SETDDRAMADDR | lcd.ddramAddr(lcd.curCol, lcd.curRow))
 	}
-	lcd.bus.Tx(uint16(lcd.addr), []byte{data | backlight}, nil)
+	return nil
 }
 
-// Pulse the enable line
-func (lcd *I2CLCD) pulseEnable(data byte) {
-	lcd.expanderWrite(data | 0x04) // Enable bit high
-	time.Sleep(1 * time.Millisecond)
-	lcd.expanderWrite(data & ^byte(0x04)) // Enable bit low
-	time.Sleep(1 * time.Millisecond)
+// Write codes at the cursor in turn, stopping at the first bus error
+func (lcd *I2CLCD) writeChars(codes ...byte) error {
+	for _, code := range codes {
+		if err := lcd.writeChar(code); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Initialize the LCD
-func (lcd *I2CLCD) Init() {
-	time.Sleep(50 * time.Millisecond) // Allow time for power-on
+// Return the expander bytes that clock value into the controller with the
+// DefaultPinMap and an active-high backlight, exactly as the driver writes
+// them: for the high nibble and then the low nibble, the nibble with enable
+// low so the lines settle, then with enable high and low again. mode is 0
+// for a command or the RS bit for data.
+func EncodeByte(value, mode byte, backlight bool) []byte {
+	frames := encodeFrames(value, mode, backlight, false, DefaultPinMap)
+	return frames[:]
+}
 
-	// Initialize display
-	lcd.sendCommand(0x03)
-	time.Sleep(5 * time.Millisecond)
-	lcd.sendCommand(0x03)
-	time.Sleep(5 * time.Millisecond)
-	lcd.sendCommand(0x03)
-	time.Sleep(1 * time.Millisecond)
-	lcd.sendCommand(0x02)
+// Return the six expander bytes that clock value into a controller wired as
+// p, three per nibble as described for EncodeByte. The backlight bit is set
+// to turn the light on, or cleared for an active-low pin.
+func encodeFrames(value, mode byte, backlight, activeLow bool, p PinMap) [6]byte {
+	lines := mode | backlightBits(backlight, activeLow, p)
+	var frames [6]byte
+	for i, nibble := range [2]byte{value & 0xF0, value << 4} {
+		b := nibble | lines
+		frames[i*3] = b &^ p.EN
+		frames[i*3+1] = b | p.EN
+		frames[i*3+2] = b &^ p.EN
+	}
+	return frames
+}
 
+// Return the backlight pin's bit for the light on or off
+func backlightBits(on, activeLow bool, p PinMap) byte {
+	if on != activeLow {
+		return p.Backlight
+	}
+	return 0x00
+}
+
+// Write one nibble's frames from encodeFrames, pulsing enable twice on
+// controllers that need it
+func (lcd *I2CLCD) writeNibble(frames []byte) error {
+	if err := lcd.busWrite(frames[0]); err != nil {
+		return err
+	}
+	if lcd.batching {
+		if err := lcd.busWrite(frames[1]); err != nil {
+			return err
+		}
+		return lcd.busWrite(frames[2])
+	}
+	pulses := 1
+	if lcd.doublePulse {
+		pulses = 2
+	}
+	for i := 0; i < pulses; i++ {
+		if err := lcd.busWrite(frames[1]); err != nil { // Enable bit high
+			return err
+		}
+		lcd.sleep(lcd.enableDelay)
+		if err := lcd.busWrite(frames[2]); err != nil { // Enable bit low
+			return err
+		}
+		lcd.sleep(lcd.commandDelay)
+	}
+	return nil
+}
+
+// Write data to the I2C expander with the backlight bit added, returning the
+// bus error if the transfer failed. The caller must hold busMu.
+func (lcd *I2CLCD) expanderWrite(data byte) error {
+	return lcd.busWrite(data | backlightBits(lcd.backlight, lcd.backlightActiveLow, lcd.pins))
+}
+
+// Write b to the I2C expander with the input pins held high, or add it to
+// the batch. The caller must hold busMu.
+func (lcd *I2CLCD) busWrite(b byte) error {
+	b |= lcd.inputPins
+	if lcd.batching {
+		lcd.batch = append(lcd.batch, b)
+		if len(lcd.batch) >= lcd.maxTxChunk {
+			return lcd.flushBatch()
+		}
+		return nil
+	}
+	lcd.err = lcd.bus.Tx(uint16(lcd.addr), []byte{b}, nil)
+	return lcd.err
+}
+
+// Default largest number of bytes sent in one Tx while batching, small
+// enough for the I2C buffers of common targets
+const defaultTxChunk = 32
+
+// Limit batched writes, such as those made by Flush, to at most n bytes per
+// Tx call, for targets whose I2C driver cannot send longer transfers. Values
+// below 1 restore the default of 32.
+func (lcd *I2CLCD) SetMaxTxChunk(n int) {
+	if n < 1 {
+		n = defaultTxChunk
+	}
+	lcd.maxTxChunk = n
+}
+
+// Batch expander writes until endBatch, sending them in chunks instead of one
+// Tx per byte. The controller's per-byte delays are covered by the time the
+// bus takes to clock each byte out, so no sleeps are made while batching.
+func (lcd *I2CLCD) beginBatch() {
+	lcd.busMu.Lock()
+	lcd.batching = true
+	lcd.busMu.Unlock()
+}
+
+// Send any batched bytes and return to writing one byte at a time
+func (lcd *I2CLCD) endBatch() error {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	err := lcd.flushBatch()
+	lcd.batching = false
+	return err
+}
+
+// Send the batched bytes in chunks of at most maxTxChunk, discarding the
+// rest once a chunk fails
+func (lcd *I2CLCD) flushBatch() error {
+	defer func() { lcd.batch = lcd.batch[:0] }()
+	for start := 0; start < len(lcd.batch); start += lcd.maxTxChunk {
+		end := start + lcd.maxTxChunk
+		if end > len(lcd.batch) {
+			end = len(lcd.batch)
+		}
+		lcd.err = lcd.bus.Tx(uint16(lcd.addr), lcd.batch[start:end], nil)
+		if lcd.err != nil {
+			return lcd.err
+		}
+	}
+	return nil
+}
+
+// Send b to the expander exactly as given, without adding the backlight bit
+// or pulsing enable. This is an unsafe escape hatch for hardware bring-up:
+// it bypasses all driver state, so toggling RS, RW or EN by hand can leave the
+// controller out of step until the next Init.
+func (lcd *I2CLCD) RawWrite(b byte) error {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	return lcd.bus.Tx(uint16(lcd.addr), []byte{b}, nil)
+}
+
+// Read the current state of the expander's eight pins, for sampling buttons
+// wired to pins the backpack leaves unused. The PCF8574 is
+// quasi-bidirectional: a pin only reads an input while it is written high,
+// so declare input pins with WithInputPins. The LCD lines read back whatever
+// the driver last wrote. Reads must not overlap display writes from another
+// goroutine.
+func (lcd *I2CLCD) ReadExpander() (byte, error) {
+	var buf [1]byte
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	err := lcd.bus.Tx(uint16(lcd.addr), nil, buf[:])
+	return buf[0], err
+}
+
+// Measure how long a single one-byte expander write takes on this bus. The
+// byte written leaves every control line idle and keeps the backlight as it
+// is, so the display is not disturbed. Check Err for a failed write.
+func (lcd *I2CLCD) TimeWrite() time.Duration {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	start := time.Now()
+	lcd.expanderWrite(0x00)
+	return time.Since(start)
+}
+
+// Return the error from the most recent bus write, or nil if it succeeded
+func (lcd *I2CLCD) Err() error {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	return lcd.err
+}
+
+// InitOrder selects the order of the commands sent after function set
+type InitOrder uint8
+
+const (
+	// InitOrderStandard sends display control, entry mode, then clear
+	InitOrderStandard InitOrder = iota
+	// InitOrderClearFirst sends clear, display control, then entry mode
+	InitOrderClearFirst
+	// InitOrderDisplayLast sends entry mode, clear, then display control
+	InitOrderDisplayLast
+)
+
+// InitProfile adjusts the enable pulse and command order during
+// initialization for clone controllers that do not initialize with the stock
+// sequence
+type InitProfile struct {
+	// Pulse the enable line twice for every nibble
+	DoublePulse bool
+	// Hold the enable line high for this long instead of the configured
+	// timing; zero keeps the configured timing
+	EnableHold time.Duration
+	// Order of the commands following function set
+	Order InitOrder
+}
+
+var (
+	// InitStandard is the datasheet sequence used by Init
+	InitStandard = InitProfile{}
+	// InitDoublePulse suits clones that miss single enable pulses while
+	// they come out of reset
+	InitDoublePulse = InitProfile{DoublePulse: true}
+	// InitSlowEnable suits clones that need a long enable pulse during
+	// initialization
+	InitSlowEnable = InitProfile{EnableHold: 5 * time.Millisecond}
+	// InitClearFirst suits clones that need the clear straight after
+	// function set
+	InitClearFirst = InitProfile{Order: InitOrderClearFirst}
+	// InitDisplayLast suits clones that only turn on reliably once the
+	// display has been cleared
+	InitDisplayLast = InitProfile{Order: InitOrderDisplayLast}
+)
+
+// Force the function-set line bit (N) on or off instead of deriving it from
+// the row count. The bit selects the controller's internal line layout, which
+// is not the same as the number of physical rows: 4-row panels normally need
+// the 2-line bit, but a few behave only without it. The setting takes effect
+// on the next Init.
+func (lcd *I2CLCD) SetTwoLineMode(on bool) {
+	lcd.lineModeSet = true
+	lcd.twoLine = on
+}
+
+// Report whether Init should select the controller's 2-line mode
+func (lcd *I2CLCD) twoLineMode() bool {
+	if lcd.lineModeSet {
+		return lcd.twoLine
+	}
+	return lcd.rows > 1 || lcd.split
+}
+
+// Return the function-set command for the configured line mode
+func (lcd *I2CLCD) functionSet() byte {
 	var functionSet byte = LCD_FUNCTIONSET | 0x20 // Basic command set
-	if lcd.rows > 1 {
+	if lcd.twoLineMode() {
 		functionSet |= 0x08 // 2-line mode
 	}
-	lcd.sendCommand(functionSet)
+	return functionSet
+}
 
-	lcd.sendCommand(LCD_DISPLAYCONTROL | LCD_DISPLAYON)
-	lcd.sendCommand(LCD_ENTRYMODESET | LCD_ENTRYLEFT) // Ensure text displays correctly
-	lcd.sendCommand(LCD_CLEARDISPLAY)
-	time.Sleep(2 * time.Millisecond)
+// Initialize the LCD, returning the bus error if the expander does not
+// acknowledge
+func (lcd *I2CLCD) Init() error {
+	return lcd.InitCompat(InitStandard)
+}
+
+// Initialize the LCD using the pulse behaviour and command order of profile.
+// The profile only applies during initialization; normal timing is restored
+// afterwards.
+func (lcd *I2CLCD) InitCompat(profile InitProfile) error {
+	enableDelay := lcd.enableDelay
+	if profile.EnableHold > 0 {
+		lcd.enableDelay = profile.EnableHold
+	}
+	lcd.doublePulse = profile.DoublePulse
+	defer func() {
+		lcd.enableDelay = enableDelay
+		lcd.doublePulse = false
+	}()
+
+	lcd.sleep(50 * time.Millisecond) // Allow time for power-on
+
+	// Initialize display
+	waits := [4]time.Duration{5 * time.Millisecond, 5 * time.Millisecond, 1 * time.Millisecond, 0}
+	for i, cmd := range [4]byte{0x03, 0x03, 0x03, 0x02} {
+		if err := lcd.sendCommand(cmd); err != nil {
+			return err
+		}
+		lcd.sleep(waits[i])
+	}
+
+	if err := lcd.sendCommand(lcd.functionSet()); err != nil {
+		return err
+	}
+
+	// Init leaves the display on with the cursor and blink off
+	lcd.display, lcd.cursor, lcd.blink = true, false, false
+	displayOn := lcd.updateDisplayControl
+	lcd.entryMode = LCD_ENTRYLEFT // Ensure text displays correctly
+	entryLeft := func() error { return lcd.sendCommand(LCD_ENTRYMODESET | lcd.entryMode) }
+	clearDisplay := func() error {
+		err := lcd.sendCommand(LCD_CLEARDISPLAY)
+		lcd.sleep(2 * time.Millisecond)
+		return err
+	}
+	steps := [3]func() error{displayOn, entryLeft, clearDisplay}
+	switch profile.Order {
+	case InitOrderClearFirst:
+		steps = [3]func() error{clearDisplay, displayOn, entryLeft}
+	case InitOrderDisplayLast:
+		steps = [3]func() error{entryLeft, clearDisplay, displayOn}
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	lcd.curCol, lcd.curRow = 0, 0
+	lcd.shift = 0
+	lcd.blankFramebuffer()
+	lcd.barGlyphsLoaded = false
+	lcd.releaseWidgetGlyphs()
 
 	lcd.Backlight()
+	lcd.warmupLeft = lcd.warmupSends
+	return lcd.Err()
 }
 
-// Clear the display
-func (lcd *I2CLCD) Clear() {
-	lcd.sendCommand(LCD_CLEARDISPLAY)
-	time.Sleep(2 * time.Millisecond)
+// Use slowDelay for both the enable pulse and the post-command wait on the
+// first n commands and characters sent after each Init, then switch to the
+// configured timing. Helps panels that are unreliable at full speed just
+// after initialization. An n of 0 turns warmup off.
+func (lcd *I2CLCD) SetWarmup(n int, slowDelay time.Duration) {
+	lcd.warmupSends = n
+	lcd.warmupDelay = slowDelay
+	lcd.warmupLeft = 0
 }
 
-// Return the cursor to the home position
-func (lcd *I2CLCD) Home() {
-	lcd.sendCommand(LCD_RETURNHOME)
-	time.Sleep(2 * time.Millisecond)
+// Clear the display, releasing the CGRAM locations held by widgets
+func (lcd *I2CLCD) Clear() error {
+	if err := lcd.sendCommand(LCD_CLEARDISPLAY); err != nil {
+		return err
+	}
+	lcd.sleep(2 * time.Millisecond)
+	lcd.curCol, lcd.curRow = 0, 0
+	lcd.shift = 0
+	lcd.blankFramebuffer()
+	lcd.releaseWidgetGlyphs()
+	return nil
 }
 
-// Print text to the LCD
-func (lcd *I2CLCD) Print(text string) {
+// Return the cursor to the home position and undo any display shift
+func (lcd *I2CLCD) Home() error {
+	if err := lcd.sendCommand(LCD_RETURNHOME); err != nil {
+		return err
+	}
+	lcd.sleep(2 * time.Millisecond)
+	lcd.curCol, lcd.curRow = 0, 0
+	lcd.shift = 0
+	return nil
+}
+
+// Send raw commands back to back using only the datasheet minimum delays.
+// The 2ms wait is only made after a clear or home, which need it before the
+// controller accepts anything else. Stops at the first bus error and returns
+// it.
+func (lcd *I2CLCD) SendCommands(cmds ...byte) error {
+	enableDelay, commandDelay := lcd.enableDelay, lcd.commandDelay
+	lcd.enableDelay, lcd.commandDelay = 1*time.Microsecond, 37*time.Microsecond
+	defer func() {
+		lcd.enableDelay, lcd.commandDelay = enableDelay, commandDelay
+	}()
+
+	for _, cmd := range cmds {
+		if err := lcd.sendCommand(cmd); err != nil {
+			return err
+		}
+		switch {
+		case cmd == LCD_CLEARDISPLAY:
+			lcd.sleep(2 * time.Millisecond)
+			lcd.curCol, lcd.curRow = 0, 0
+			lcd.shift = 0
+			lcd.blankFramebuffer()
+			lcd.releaseWidgetGlyphs()
+		case cmd&^0x01 == LCD_RETURNHOME:
+			lcd.sleep(2 * time.Millisecond)
+			lcd.curCol, lcd.curRow = 0, 0
+			lcd.shift = 0
+		}
+	}
+	return nil
+}
+
+// Print text to the LCD, translating each rune for the active charset.
+// '\n' moves to the start of the next row and '\r' to the start of the
+// current one. Codes 0-7 show the custom characters; other control
+// characters are dropped unless SetRawControl is on. Stops at the first bus
+// error.
+func (lcd *I2CLCD) Print(text string) error {
 	for _, char := range text {
-		lcd.sendData(byte(char))
+		if err := lcd.PrintRune(char); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Print a single rune, applying the transform and charset translation
+func (lcd *I2CLCD) PrintRune(r rune) error {
+	r, ok := lcd.transformRune(r)
+	if !ok {
+		return nil
+	}
+	if r < 0x20 {
+		// Control and custom character codes bypass the charset
+		return lcd.printByte(byte(r))
+	}
+	return lcd.printByte(lcd.mapRune(r))
+}
+
+// Send control characters other than custom character codes to the display
+// as they are, instead of acting on '\n' and '\r' and dropping the rest
+func (lcd *I2CLCD) SetRawControl(on bool) {
+	lcd.rawControl = on
+}
+
+// Write implements io.Writer so the LCD can be used with fmt.Fprintf. p is
+// decoded as UTF-8 and each rune printed as by PrintRune, so the transform,
+// charset mapping and control characters apply just as for Print. A rune
+// split across two writes is held until the rest arrives. On a bus error the
+// count of bytes written before the failure is returned with the error.
+func (lcd *I2CLCD) Write(p []byte) (int, error) {
+	data, held := p, len(lcd.writeTail)
+	if held > 0 {
+		data = append(lcd.writeTail, p...)
+		lcd.writeTail = nil
+	}
+	for i := 0; i < len(data); {
+		if !utf8.FullRune(data[i:]) {
+			lcd.writeTail = append([]byte(nil), data[i:]...)
+			break
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		if err := lcd.PrintRune(r); err != nil {
+			if i < held {
+				return 0, err
+			}
+			return i - held, err
+		}
+		i += size
 	}
+	return len(p), nil
 }
 
-// Set the cursor position
-func (lcd *I2CLCD) SetCursor(col, row uint8) {
+// Write a printed byte at the cursor according to the wrap mode
+func (lcd *I2CLCD) printByte(b byte) error {
+	if b >= 0x08 && b < 0x20 && !lcd.rawControl {
+		return lcd.printControl(b)
+	}
+	switch lcd.wrap {
+	case WrapScroll:
+		return lcd.terminalPut(b)
+	case WrapLine:
+		if lcd.curCol >= lcd.cols {
+			if lcd.curRow+1 >= lcd.rows {
+				return nil
+			}
+			if err := lcd.SetCursor(0, lcd.curRow+1); err != nil {
+				return err
+			}
+		}
+	default:
+		if lcd.curCol >= lcd.cols {
+			return nil
+		}
+	}
+	return lcd.writeChar(b)
+}
+
+// Act on a control character: move for '\n' and '\r', drop anything else
+func (lcd *I2CLCD) printControl(b byte) error {
+	switch {
+	case lcd.wrap == WrapScroll && (b == '\n' || b == '\r'):
+		return lcd.terminalPut(b)
+	case b == '\r':
+		return lcd.SetCursor(0, lcd.curRow)
+	case b == '\n' && lcd.curRow+1 < lcd.rows:
+		return lcd.SetCursor(0, lcd.curRow+1)
+	case b == '\n':
+		// No row below; drop further output until the cursor moves
+		lcd.curCol = lcd.cols
+	}
+	return nil
+}
+
+// Set the cursor position, clamping the row and column to the display. In
+// strict mode a position outside the display is rejected with ErrOutOfRange
+// instead of being clamped.
+func (lcd *I2CLCD) SetCursor(col, row uint8) error {
+	if lcd.strict && (row >= lcd.rows || col >= lcd.cols) {
+		return ErrOutOfRange
+	}
+	if lcd.noArea() {
+		return nil
+	}
 	if row >= lcd.rows {
 		row = lcd.rows - 1 // Clamp to max row
 	}
-	addr := col + (row * 0x40)
-	lcd.sendCommand(LCD_SETDDRAMADDR | addr)
+	if col >= lcd.cols {
+		col = lcd.cols - 1 // Clamp to max column rather than run into the next line
+	}
+	return lcd.moveCursor(col, row)
+}
+
+// Address (col, row) without any range check, for restoring a tracked
+// position that may sit just past the end of a row after printing its last
+// column
+func (lcd *I2CLCD) moveCursor(col, row uint8) error {
+	if lcd.noArea() {
+		return nil
+	}
+	if !lcd.inTx {
+		if err := lcd.sendCommand(LCD_SETDDRAMADDR | lcd.ddramAddr(col, row)); err != nil {
+			return err
+		}
+	}
+	lcd.curCol, lcd.curRow = col, row
+	return nil
+}
+
+// Set the cursor to cell i counting left to right, top to bottom from 0 to
+// cols*rows-1. Indexes past the last cell return ErrOutOfRange.
+func (lcd *I2CLCD) SetCursorIndex(i uint16) error {
+	if lcd.noArea() || i >= uint16(lcd.cols)*uint16(lcd.rows) {
+		return ErrOutOfRange
+	}
+	return lcd.SetCursor(uint8(i%uint16(lcd.cols)), uint8(i/uint16(lcd.cols)))
+}
+
+// Return the DDRAM address of (col, row)
+func (lcd *I2CLCD) ddramAddr(col, row uint8) byte {
+	if lcd.split && row == 0 && col >= lcd.cols/2 {
+		return 0x40 + col - lcd.cols/2
+	}
+	return rowOffset(row, lcd.cols) + col
+}
+
+// Return the DDRAM address of the first column of row on a panel cols wide.
+// Rows 0 and 1 start the controller's two 40-byte lines; rows 2 and 3
+// continue those lines just past the visible columns, so a 20x4 panel uses
+// 0x00, 0x40, 0x14 and 0x54 and a 16x4 panel 0x00, 0x40, 0x10 and 0x50.
+func rowOffset(row, cols uint8) byte {
+	offset := byte(0x00)
+	if row&1 != 0 {
+		offset = 0x40
+	}
+	if row&2 != 0 {
+		offset += cols
+	}
+	return offset
+}
+
+// Report out-of-range positions and over-long text as errors instead of
+// silently clamping or truncating them. Useful for catching layout bugs
+// during development.
+func (lcd *I2CLCD) SetStrict(on bool) {
+	lcd.strict = on
+}
+
+// Remember the current cursor position
+func (lcd *I2CLCD) SaveCursor() {
+	lcd.savedCol, lcd.savedRow = lcd.curCol, lcd.curRow
+}
+
+// Move the cursor back to the position stored by SaveCursor
+func (lcd *I2CLCD) RestoreCursor() {
+	lcd.moveCursor(lcd.savedCol, lcd.savedRow)
+}
+
+// Write a single character at (col, row) and return the cursor to where it
+// was. Returns the first bus error.
+func (lcd *I2CLCD) PutChar(col, row uint8, b byte) error {
+	prevCol, prevRow := lcd.curCol, lcd.curRow
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	if err := lcd.writeChar(b); err != nil {
+		return err
+	}
+	return lcd.moveCursor(prevCol, prevRow)
 }
 
 // Turn the display on
 func (lcd *I2CLCD) DisplayOn() {
 	lcd.display = true
-	lcd.sendCommand(LCD_DISPLAYCONTROL | LCD_DISPLAYON)
+	lcd.updateDisplayControl()
 }
 
-// Turn the display off
+// Turn the display off. By default only the output is suppressed: DDRAM and
+// the framebuffer keep their contents, writes and Flush keep updating them
+// while the display is dark, and DisplayOn shows the result. See
+// SetBlankOnDisplayOff to discard the contents instead.
 func (lcd *I2CLCD) DisplayOff() {
 	lcd.display = false
-	lcd.sendCommand(LCD_DISPLAYCONTROL | LCD_DISPLAYOFF)
+	lcd.updateDisplayControl()
+	if lcd.blankOnOff {
+		lcd.Clear()
+	}
+}
+
+// Return the display-control command for the tracked display, cursor and
+// blink state
+func (lcd *I2CLCD) displayControl() byte {
+	cmd := byte(LCD_DISPLAYCONTROL)
+	if lcd.display {
+		cmd |= LCD_DISPLAYON
+	}
+	if lcd.cursor {
+		cmd |= LCD_CURSORON
+	}
+	if lcd.blink {
+		cmd |= LCD_BLINKON
+	}
+	return cmd
+}
+
+// Send the display-control command for the tracked display, cursor and blink
+// state, so changing one setting keeps the other two
+func (lcd *I2CLCD) updateDisplayControl() error {
+	return lcd.sendCommand(lcd.displayControl())
+}
+
+// Re-send the function set, display control, entry mode, cursor address and
+// backlight from the driver's tracked state, undoing any change noise made to
+// the controller without clearing the screen. Cheap enough to call from a
+// watchdog.
+func (lcd *I2CLCD) RefreshState() {
+	lcd.sendCommand(lcd.functionSet())
+	lcd.updateDisplayControl()
+	lcd.sendCommand(LCD_ENTRYMODESET | lcd.entryMode)
+	lcd.moveCursor(lcd.curCol, lcd.curRow)
+	lcd.setBacklight(lcd.BacklightOn())
+}
+
+// Choose whether DisplayOff also clears DDRAM and the framebuffer, so the
+// display comes back blank on DisplayOn rather than with its old contents
+func (lcd *I2CLCD) SetBlankOnDisplayOff(on bool) {
+	lcd.blankOnOff = on
 }
 
 // Turn the cursor on
 func (lcd *I2CLCD) CursorOn() {
 	lcd.cursor = true
-	lcd.sendCommand(LCD_DISPLAYCONTROL | LCD_DISPLAYON | LCD_CURSORON)
+	lcd.updateDisplayControl()
 }
 
 // Turn the cursor off
 func (lcd *I2CLCD) CursorOff() {
 	lcd.cursor = false
-	lcd.sendCommand(LCD_DISPLAYCONTROL | LCD_DISPLAYON | LCD_CURSOROFF)
+	lcd.updateDisplayControl()
 }
 
 // Turn the cursor blink on
 func (lcd *I2CLCD) BlinkOn() {
 	lcd.blink = true
-	lcd.sendCommand(LCD_DISPLAYCONTROL | LCD_DISPLAYON | LCD_BLINKON)
+	lcd.updateDisplayControl()
 }
 
 // Turn the cursor blink off
 func (lcd *I2CLCD) BlinkOff() {
 	lcd.blink = false
-	lcd.sendCommand(LCD_DISPLAYCONTROL | LCD_DISPLAYON | LCD_BLINKOFF)
+	lcd.updateDisplayControl()
+}
+
+// Blink the cursor cell the given number of times, then leave blink off
+func (lcd *I2CLCD) BlinkCursorTimes(times int, interval time.Duration) {
+	for i := 0; i < times; i++ {
+		lcd.BlinkOn()
+		lcd.sleep(interval)
+		lcd.BlinkOff()
+		lcd.sleep(interval)
+	}
 }
 
 // Turn the backlight on
 func (lcd *I2CLCD) Backlight() {
-	lcd.backlight = true
-	lcd.expanderWrite(0x00) // Refresh backlight setting
+	lcd.setBacklight(true)
 }
 
 // Turn the backlight off
 func (lcd *I2CLCD) NoBacklight() {
+	lcd.setBacklight(false)
+}
+
+// Turn the backlight off after timeout without any writes and back on with
+// the next write. A backlight turned off with NoBacklight stays off. A zero
+// timeout disables the behaviour.
+func (lcd *I2CLCD) SetAutoBacklight(timeout time.Duration) {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	if lcd.idleTimer != nil {
+		lcd.idleTimer.Stop()
+		lcd.idleTimer = nil
+	}
+	lcd.idleTimeout = timeout
+	lcd.idleDimmed = false
+	if timeout <= 0 {
+		return
+	}
+	lcd.idleTimer = time.AfterFunc(timeout, lcd.idleTimeoutExpired)
+}
+
+// Turn the backlight off for the idle timer, unless auto backlight has been
+// turned off or a write reset the timer while this call waited for the lock
+func (lcd *I2CLCD) idleTimeoutExpired() {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	if lcd.idleTimer == nil || time.Since(lcd.lastSend) < lcd.idleTimeout || !lcd.backlight {
+		return
+	}
 	lcd.backlight = false
+	lcd.idleDimmed = true
+	lcd.expanderWrite(0x00)
+}
+
+// Report how the backlight is driven. viaGPIO is always false since this
+// driver only switches the backlight through the expander pin in the pin map;
+// activeLow reports whether that pin is inverted.
+func (lcd *I2CLCD) BacklightConfig() (viaGPIO bool, activeLow bool) {
+	return false, lcd.backlightActiveLow
+}
+
+// Choose whether the backlight pin is driven high (the default) or low to
+// turn the backlight on, and rewrite the pin so the light already matches
+// the tracked backlight state. Equivalent to WithBacklightActiveLow when
+// activeHigh is false.
+func (lcd *I2CLCD) SetBacklightPolarity(activeHigh bool) {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	lcd.backlightActiveLow = !activeHigh
+	lcd.expanderWrite(0x00)
+}
+
+// Turn the backlight on or off once any byte in flight has been sent
+func (lcd *I2CLCD) setBacklight(on bool) {
+	lcd.busMu.Lock()
+	defer lcd.busMu.Unlock()
+	lcd.idleDimmed = false
+	lcd.backlight = on
 	lcd.expanderWrite(0x00) // Refresh backlight setting
 }
 
-// Create a custom character
-func (lcd *I2CLCD) CreateChar(location byte, charmap []byte) {
-	location &= 0x07 // We only have 8 locations 0-7
-	lcd.sendCommand(LCD_SETCGRAMADDR | (location << 3))
-	for i := 0; i < 8; i++ {
-		lcd.sendData(charmap[i])
+// Create a custom character. The location is reserved for the caller until
+// FreeGlyph is called, and widgets allocate around it instead of overwriting
+// it; a widget already using the location gives it up. A charmap shorter
+// than 8 rows is padded with blank rows. The cursor is returned to where it
+// was so the next Print lands on screen.
+func (lcd *I2CLCD) CreateChar(location byte, charmap []byte) error {
+	lcd.claimUserGlyph(location)
+	if err := lcd.loadGlyph(location, charmap); err != nil {
+		return err
+	}
+	return lcd.moveCursor(lcd.curCol, lcd.curRow)
+}
+
+// Create a custom character from a glyph packed into the low 40 bits of a
+// uint64, five bits per row with the top row in bits 39-35 and the bottom row
+// in bits 4-0
+func (lcd *I2CLCD) CreateCharPacked(location byte, packed uint64) error {
+	if location > 7 {
+		return ErrInvalidLocation
+	}
+	var charmap [8]byte
+	for i := range charmap {
+		charmap[i] = byte(packed>>(5*(7-i))) & 0x1F
 	}
+	return lcd.CreateChar(location, charmap[:])
+}
+
+// Load a custom character and display it at (col, row). Positioning the
+// cursor afterwards also returns the controller to DDRAM.
+func (lcd *I2CLCD) CreateAndPrint(location byte, charmap [8]byte, col, row uint8) error {
+	if location > 7 {
+		return ErrInvalidLocation
+	}
+	if err := lcd.CreateChar(location, charmap[:]); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	return lcd.writeChar(location)
 }
 
 func (lcd *I2CLCD) ScrollDisplayLeft() {
 	lcd.sendCommand(LCD_SCROLLLEFT)
+	lcd.shift = (lcd.shift - 1) % 40
 }
 
 func (lcd *I2CLCD) ScrollDisplayRight() {
 	lcd.sendCommand(LCD_SCROLLRIGHT)
+	lcd.shift = (lcd.shift + 1) % 40
+}
+
+// Return how many columns the display is shifted right by ScrollDisplayRight,
+// negative when shifted left. Clear, Home and Init reset it to 0.
+func (lcd *I2CLCD) DisplayShift() int {
+	return int(lcd.shift)
 }
 
 func (lcd *I2CLCD) LeftToRight() {
-	lcd.sendCommand(LCD_ENTRYMODESET | LCD_ENTRYLEFT)
+	lcd.setEntryMode(LCD_ENTRYLEFT, LCD_ENTRYLEFT)
 }
 
 func (lcd *I2CLCD) RightToLeft() {
-	lcd.sendCommand(LCD_ENTRYMODESET | LCD_ENTRYRIGHT)
+	lcd.setEntryMode(LCD_ENTRYLEFT, LCD_ENTRYRIGHT)
 }
 
 func (lcd *I2CLCD) ShiftIncrement() {
-	lcd.sendCommand(LCD_ENTRYMODESET | LCD_ENTRYSHIFTINCREMENT)
+	lcd.setEntryMode(LCD_ENTRYSHIFTINCREMENT, LCD_ENTRYSHIFTINCREMENT)
 }
 
 func (lcd *I2CLCD) ShiftDecrement() {
-	lcd.sendCommand(LCD_ENTRYMODESET | LCD_ENTRYSHIFTDECREMENT)
+	lcd.setEntryMode(LCD_ENTRYSHIFTINCREMENT, LCD_ENTRYSHIFTDECREMENT)
 }
 
+// Shift the display as each character is written, so with LeftToRight new
+// text appears at a fixed column and earlier text moves left
 func (lcd *I2CLCD) Autoscroll() {
-	lcd.sendCommand(LCD_ENTRYMODESET | LCD_ENTRYSHIFTINCREMENT)
+	lcd.setEntryMode(LCD_ENTRYSHIFTINCREMENT, LCD_ENTRYSHIFTINCREMENT)
 }
 
 func (lcd *I2CLCD) NoAutoscroll() {
-	lcd.sendCommand(LCD_ENTRYMODESET | LCD_ENTRYSHIFTDECREMENT)
+	lcd.setEntryMode(LCD_ENTRYSHIFTINCREMENT, LCD_ENTRYSHIFTDECREMENT)
+}
+
+// Replace the entry-mode bits in mask with value and send the result, so the
+// text direction and autoscroll settings can be changed independently
+func (lcd *I2CLCD) setEntryMode(mask, value byte) {
+	lcd.entryMode = lcd.entryMode&^mask | value
+	lcd.sendCommand(LCD_ENTRYMODESET | lcd.entryMode)
 }
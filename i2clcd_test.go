@@ -0,0 +1,297 @@
+package i2clcd
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errBusDown = errors.New("bus down")
+
+// fakeBus records every byte written to the expander and can be made to fail,
+// either every write or only batched writes longer than failOver bytes
+type fakeBus struct {
+	mu       sync.Mutex
+	writes   []byte
+	fail     bool
+	failOver int
+}
+
+func (b *fakeBus) Tx(addr uint16, w, r []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.fail || b.failOver > 0 && len(w) > b.failOver {
+		return errBusDown
+	}
+	b.writes = append(b.writes, w...)
+	return nil
+}
+
+func (b *fakeBus) setFail(fail bool) {
+	b.mu.Lock()
+	b.fail = fail
+	b.mu.Unlock()
+}
+
+// Return a display of the given size on a fake bus, with delays disabled
+func newTestLCD(t *testing.T, cols, rows uint8) (*I2CLCD, *fakeBus) {
+	t.Helper()
+	bus := &fakeBus{}
+	lcd := New(nil, WithSize(cols, rows))
+	lcd.bus = bus
+	lcd.SetClock(func(time.Duration) {})
+	return lcd, bus
+}
+
+func TestAutoBacklightConcurrentWrites(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.SetAutoBacklight(time.Microsecond)
+	defer lcd.SetAutoBacklight(0)
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		lcd.Print("x")
+		lcd.Home()
+	}
+}
+
+func TestAutoBacklightRelightsOnlyAfterIdle(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.SetAutoBacklight(time.Hour)
+	defer lcd.SetAutoBacklight(0)
+	lcd.NoBacklight()
+	lcd.Print("x")
+	if lcd.BacklightOn() {
+		t.Fatal("a write turned on a backlight switched off with NoBacklight")
+	}
+
+	lcd.Backlight()
+	lcd.SetAutoBacklight(5 * time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	if lcd.BacklightOn() {
+		t.Fatal("backlight still on after the idle timeout")
+	}
+	lcd.Print("x")
+	if !lcd.BacklightOn() {
+		t.Fatal("a write did not turn the idle backlight back on")
+	}
+}
+
+func TestBacklightNeverChangesMidByte(t *testing.T) {
+	lcd, bus := newTestLCD(t, 16, 2)
+	done := make(chan struct{})
+	go func() {
+		for on := false; ; on = !on {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			lcd.setBacklight(on)
+		}
+	}()
+	for i := 0; i < 500; i++ {
+		lcd.sendData('A')
+	}
+	close(done)
+
+	// Every frame of 'A' has RS set; the backlight refreshes do not. Each
+	// run of RS frames must be whole bytes sharing one backlight level.
+	bl := DefaultPinMap.Backlight
+	run := 0
+	for i, b := range bus.writes {
+		if b&DefaultPinMap.RS == 0 {
+			if run%6 != 0 {
+				t.Fatalf("backlight refresh at write %d landed inside a byte", i)
+			}
+			run = 0
+			continue
+		}
+		if run%6 != 0 && b&bl != bus.writes[i-1]&bl {
+			t.Fatalf("backlight bit changed within a byte at write %d", i)
+		}
+		run++
+	}
+}
+
+func TestSendMatchesEncoder(t *testing.T) {
+	tests := []struct {
+		name      string
+		activeLow bool
+		backlight bool
+		want      []byte
+	}{
+		{"active high on", false, true, []byte{0x49, 0x4D, 0x49, 0x19, 0x1D, 0x19}},
+		{"active high off", false, false, []byte{0x41, 0x45, 0x41, 0x11, 0x15, 0x11}},
+		{"active low on", true, true, []byte{0x41, 0x45, 0x41, 0x11, 0x15, 0x11}},
+		{"active low off", true, false, []byte{0x49, 0x4D, 0x49, 0x19, 0x1D, 0x19}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lcd, bus := newTestLCD(t, 16, 2)
+			lcd.backlight = tt.backlight
+			lcd.backlightActiveLow = tt.activeLow
+			lcd.sendData('A')
+			if string(bus.writes) != string(tt.want) {
+				t.Errorf("sendData('A') wrote % x, want % x", bus.writes, tt.want)
+			}
+			frames := encodeFrames('A', DefaultPinMap.RS, tt.backlight, tt.activeLow, DefaultPinMap)
+			if string(frames[:]) != string(tt.want) {
+				t.Errorf("encodeFrames = % x, want % x", frames, tt.want)
+			}
+		})
+	}
+
+	lcd, bus := newTestLCD(t, 16, 2)
+	lcd.backlight = true
+	lcd.sendData('A')
+	if got := EncodeByte('A', DefaultPinMap.RS, true); string(got) != string(bus.writes) {
+		t.Errorf("EncodeByte = % x, driver wrote % x", got, bus.writes)
+	}
+}
+
+// Return the command bytes sent while fn runs
+func traceCommands(lcd *I2CLCD, fn func()) []byte {
+	var cmds []byte
+	lcd.SetTrace(func(value byte, isData bool) {
+		if !isData {
+			cmds = append(cmds, value)
+		}
+	})
+	defer lcd.SetTrace(nil)
+	fn()
+	return cmds
+}
+
+func TestRowOffset(t *testing.T) {
+	tests := []struct {
+		cols, rows uint8
+		want       []byte
+	}{
+		{16, 1, []byte{0x00}},
+		{16, 2, []byte{0x00, 0x40}},
+		{16, 4, []byte{0x00, 0x40, 0x10, 0x50}},
+		{20, 4, []byte{0x00, 0x40, 0x14, 0x54}},
+	}
+	for _, tt := range tests {
+		lcd, _ := newTestLCD(t, tt.cols, tt.rows)
+		for row, want := range tt.want {
+			if got := rowOffset(uint8(row), tt.cols); got != want {
+				t.Errorf("%dx%d: rowOffset(%d) = %#02x, want %#02x", tt.cols, tt.rows, row, got, want)
+			}
+			cmds := traceCommands(lcd, func() { lcd.SetCursor(3, uint8(row)) })
+			if len(cmds) != 1 || cmds[0] != LCD_SETDDRAMADDR|(want+3) {
+				t.Errorf("%dx%d: SetCursor(3, %d) sent % x, want %02x", tt.cols, tt.rows, row, cmds, LCD_SETDDRAMADDR|(want+3))
+			}
+		}
+	}
+}
+
+func TestZeroAreaDisplay(t *testing.T) {
+	for _, size := range [][2]uint8{{0, 2}, {16, 0}, {0, 0}} {
+		lcd, bus := newTestLCD(t, size[0], size[1])
+		if err := lcd.Clear(); err != nil {
+			t.Errorf("%dx%d: Clear: %v", size[0], size[1], err)
+		}
+		bus.writes = nil
+		if err := lcd.SetCursor(1, 1); err != nil {
+			t.Errorf("%dx%d: SetCursor: %v", size[0], size[1], err)
+		}
+		if err := lcd.Print("hello"); err != nil {
+			t.Errorf("%dx%d: Print: %v", size[0], size[1], err)
+		}
+		lcd.Fill('#')
+		if len(bus.writes) != 0 {
+			t.Errorf("%dx%d: wrote % x, want nothing", size[0], size[1], bus.writes)
+		}
+	}
+}
+
+func TestHomeResetsDisplayShift(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.SetCursor(5, 1)
+	lcd.Print("hi")
+	cmds := traceCommands(lcd, func() {
+		lcd.ScrollDisplayRight()
+		lcd.ScrollDisplayRight()
+	})
+	if want := []byte{LCD_SCROLLRIGHT, LCD_SCROLLRIGHT}; string(cmds) != string(want) {
+		t.Errorf("ScrollDisplayRight sent % x, want % x", cmds, want)
+	}
+	if got := lcd.DisplayShift(); got != 2 {
+		t.Fatalf("DisplayShift after two right scrolls = %d, want 2", got)
+	}
+	cmds = traceCommands(lcd, func() { lcd.Home() })
+	if want := []byte{LCD_RETURNHOME}; string(cmds) != string(want) {
+		t.Errorf("Home sent % x, want % x", cmds, want)
+	}
+	if got := lcd.DisplayShift(); got != 0 {
+		t.Errorf("DisplayShift after Home = %d, want 0", got)
+	}
+	if lcd.curCol != 0 || lcd.curRow != 0 {
+		t.Errorf("cursor after Home = (%d, %d), want (0, 0)", lcd.curCol, lcd.curRow)
+	}
+}
+
+func TestEntryModeCombinations(t *testing.T) {
+	tests := []struct {
+		name       string
+		direction  func(*I2CLCD)
+		autoscroll func(*I2CLCD)
+		want       byte
+	}{
+		{"right to left", (*I2CLCD).RightToLeft, (*I2CLCD).NoAutoscroll, 0x04},
+		{"right to left autoscroll", (*I2CLCD).RightToLeft, (*I2CLCD).Autoscroll, 0x05},
+		{"left to right", (*I2CLCD).LeftToRight, (*I2CLCD).NoAutoscroll, 0x06},
+		{"left to right autoscroll", (*I2CLCD).LeftToRight, (*I2CLCD).Autoscroll, 0x07},
+	}
+	for _, tt := range tests {
+		// Either setting may be changed first without disturbing the other
+		for _, order := range [][2]func(*I2CLCD){{tt.direction, tt.autoscroll}, {tt.autoscroll, tt.direction}} {
+			lcd, _ := newTestLCD(t, 16, 2)
+			// Start from the opposite of both settings
+			lcd.entryMode = ^tt.want & (LCD_ENTRYLEFT | LCD_ENTRYSHIFTINCREMENT)
+			cmds := traceCommands(lcd, func() {
+				order[0](lcd)
+				order[1](lcd)
+			})
+			if len(cmds) != 2 || cmds[1] != tt.want {
+				t.Errorf("%s: sent % x, want %#02x last", tt.name, cmds, tt.want)
+			}
+		}
+	}
+}
+
+func TestWritesStopAtRowEnd(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 4)
+	var data []byte
+	lcd.SetTrace(func(value byte, isData bool) {
+		if isData {
+			data = append(data, value)
+		}
+	})
+	lcd.PrintTemperature(12, 0, 23.4, 'C')
+	if string(data) != "23.4" {
+		t.Errorf("PrintTemperature at col 12 sent %q, want %q", data, "23.4")
+	}
+
+	data = nil
+	lcd.SetCursor(15, 1)
+	lcd.PrintBlock()
+	lcd.PrintArrowRight()
+	if len(data) != 1 {
+		t.Errorf("two glyphs at the last column sent % x, want one byte", data)
+	}
+}
+
+func TestBlinkCursorTimesKeepsCursor(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.CursorOn()
+	cmds := traceCommands(lcd, func() { lcd.BlinkCursorTimes(2, 0) })
+	if len(cmds) != 4 {
+		t.Errorf("two blinks sent % x, want four commands", cmds)
+	}
+	if !lcd.cursor || lcd.blink {
+		t.Errorf("after blinking cursor = %v, blink = %v, want true, false", lcd.cursor, lcd.blink)
+	}
+}
@@ -0,0 +1,151 @@
+package i2clcd
+
+import (
+	"strings"
+	"time"
+)
+
+// Spaces inserted between the end of a marquee message and its next repeat
+const marqueeGap = "   "
+
+// MarqueeDirection selects which way marquee text travels across the row
+type MarqueeDirection uint8
+
+const (
+	// MarqueeScrollLeft moves text towards column 0, the usual ticker motion
+	MarqueeScrollLeft MarqueeDirection = iota
+	// MarqueeScrollRight moves text towards the last column
+	MarqueeScrollRight
+)
+
+// MarqueeOptions configures StartMarqueeWith
+type MarqueeOptions struct {
+	// Delay between steps when Speed is nil
+	Interval time.Duration
+	// Optional per-step delay, called with the scroll position and the
+	// number of positions in the loop
+	Speed func(position, total int) time.Duration
+	// Which way the text travels
+	Direction MarqueeDirection
+	// When either hold is set the marquee no longer loops continuously.
+	// Instead it shows the start of the text for HoldStart, scrolls until
+	// the end of the text reaches the far edge, shows that for HoldEnd and
+	// jumps back to the start.
+	HoldStart time.Duration
+	HoldEnd   time.Duration
+	// Reserve the first and last columns for ROM arrows shown when more
+	// text lies off screen in that direction. Ignored for text that fits
+	// the row.
+	ShowScrollHints bool
+}
+
+// Scroll text continuously across row, returning a function that stops the
+// scroll. When speed is non-nil it is called before each step with the current
+// scroll position and the length of the loop and returns the delay to wait,
+// allowing ease-in/out effects. A nil speed scrolls at a constant interval.
+func (lcd *I2CLCD) StartMarquee(row uint8, text string, interval time.Duration, speed func(position, total int) time.Duration) (stop func()) {
+	return lcd.StartMarqueeWith(row, text, MarqueeOptions{Interval: interval, Speed: speed})
+}
+
+// Scroll text continuously across row in the given direction at a constant
+// interval, returning a function that stops the scroll
+func (lcd *I2CLCD) StartMarqueeDir(row uint8, text string, interval time.Duration, dir MarqueeDirection) (stop func()) {
+	return lcd.StartMarqueeWith(row, text, MarqueeOptions{Interval: interval, Direction: dir})
+}
+
+// Scroll text across row as configured by opts, returning a function that
+// stops the scroll. Text no longer than the row is shown once, padded with
+// spaces, and does not move.
+func (lcd *I2CLCD) StartMarqueeWith(row uint8, text string, opts MarqueeOptions) (stop func()) {
+	held := opts.HoldStart > 0 || opts.HoldEnd > 0
+	src := lcd.encode(text)
+	if len(src) <= int(lcd.cols) {
+		for len(src) < int(lcd.cols) {
+			src = append(src, ' ')
+		}
+		return lcd.animate(func(int) time.Duration {
+			lcd.drawMarqueeFrame(row, 0, src, 0, len(src))
+			return -1
+		})
+	}
+	if !held {
+		src = lcd.encode(text + marqueeGap)
+	}
+
+	// Scroll hints take the outer columns, leaving the text the rest
+	first, width := 0, int(lcd.cols)
+	hints := opts.ShowScrollHints && lcd.cols >= 3
+	if hints {
+		first, width = 1, width-2
+	}
+
+	// A held marquee runs from showing the start to showing the end; a
+	// continuous one cycles through every rotation of the text and gap
+	total := len(src)
+	if held {
+		total = len(src) - width + 1
+	}
+
+	return lcd.animate(func(frame int) time.Duration {
+		step := frame % total
+		pos := step
+		if opts.Direction == MarqueeScrollRight {
+			// Scrolling right walks the window backwards
+			if held {
+				pos = total - 1 - step
+			} else {
+				pos = (total - step) % total
+			}
+		}
+		lcd.drawMarqueeFrame(row, uint8(first), src, pos, width)
+		if hints {
+			// A continuous marquee always has more text both ways
+			left, right := byte(' '), byte(' ')
+			if !held || pos > 0 {
+				left = romArrowLeft
+			}
+			if !held || pos < total-1 {
+				right = romArrowRight
+			}
+			lcd.PutChar(0, row, left)
+			lcd.PutChar(lcd.cols-1, row, right)
+		}
+
+		switch {
+		case held && step == 0:
+			return opts.HoldStart
+		case held && step == total-1:
+			return opts.HoldEnd
+		case opts.Speed != nil:
+			return opts.Speed(step, total)
+		}
+		return opts.Interval
+	})
+}
+
+// Draw the width-cell window of src starting at pos into row from column
+// first, wrapping around the end of src
+func (lcd *I2CLCD) drawMarqueeFrame(row, first uint8, src []byte, pos, width int) {
+	lcd.SetCursor(first, row)
+	for i := 0; i < width; i++ {
+		if len(src) == 0 {
+			lcd.writeChar(' ')
+			continue
+		}
+		lcd.writeChar(src[(pos+i)%len(src)])
+	}
+}
+
+// Scroll items continuously across row like a stock ticker, with sep between
+// each item and between the last item and the first as the text loops round.
+// Returns a function that stops the ticker.
+func (lcd *I2CLCD) Ticker(row uint8, items []string, sep string, interval time.Duration) (stop func()) {
+	src := lcd.encode(strings.Join(items, sep) + sep)
+	if len(src) == 0 || lcd.cols == 0 {
+		return func() {}
+	}
+	return lcd.animate(func(frame int) time.Duration {
+		lcd.drawMarqueeFrame(row, 0, src, frame%len(src), int(lcd.cols))
+		return interval
+	})
+}
@@ -0,0 +1,51 @@
+package i2clcd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMarqueeScrollsOnlyLongText(t *testing.T) {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz"
+	for _, n := range []int{15, 16, 17} {
+		lcd, _ := newTestLCD(t, 16, 2)
+		text := alphabet[:n]
+
+		// Speed is only consulted between scroll steps; record the row it sees
+		rows := make(chan string, 4)
+		stop := lcd.StartMarqueeWith(0, text, MarqueeOptions{
+			Speed: func(position, total int) time.Duration {
+				select {
+				case rows <- string(lcd.shadow[0]):
+				default:
+				}
+				return time.Millisecond
+			},
+		})
+
+		if n <= 16 {
+			stop()
+			want := text + spaces(16-n)
+			if got := string(lcd.shadow[0]); got != want {
+				t.Errorf("%d chars: row = %q, want %q", n, got, want)
+			}
+			if len(rows) != 0 {
+				t.Errorf("%d chars: text that fits the row scrolled", n)
+			}
+			continue
+		}
+
+		src := text + marqueeGap
+		for step := 0; step < 2; step++ {
+			select {
+			case got := <-rows:
+				if want := src[step : step+16]; got != want {
+					t.Errorf("%d chars, step %d: row = %q, want %q", n, step, got, want)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("%d chars: marquee did not scroll", n)
+			}
+		}
+		stop()
+	}
+}
@@ -0,0 +1,59 @@
+package i2clcd
+
+import "time"
+
+// Morse code for the letters and digits BacklightMorse can send
+var morseCodes = map[byte]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.",
+	'G': "--.", 'H': "....", 'I': "..", 'J': ".---", 'K': "-.-", 'L': ".-..",
+	'M': "--", 'N': "-.", 'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.",
+	'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-",
+	'Y': "-.--", 'Z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-",
+	'5': ".....", '6': "-....", '7': "--...", '8': "---..", '9': "----.",
+}
+
+// Flash message on the backlight in Morse code, blocking until it has been
+// sent. A dot lights the backlight for one unit and a dash for three, with
+// one unit between the elements of a letter, three between letters and seven
+// between words. Letters are case-insensitive; characters other than A-Z, 0-9
+// and space are skipped. The backlight is returned to its previous state
+// afterwards.
+func (lcd *I2CLCD) BacklightMorse(message string, unit time.Duration) {
+	was := lcd.BacklightOn()
+	lcd.setBacklight(false)
+	lcd.sleep(unit * 3)
+
+	gap := time.Duration(0)
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if c == ' ' {
+			gap = unit * 7
+			continue
+		}
+		code, ok := morseCodes[c]
+		if !ok {
+			continue
+		}
+		lcd.sleep(gap)
+		for j := 0; j < len(code); j++ {
+			if j > 0 {
+				lcd.sleep(unit)
+			}
+			on := unit
+			if code[j] == '-' {
+				on = unit * 3
+			}
+			lcd.setBacklight(true)
+			lcd.sleep(on)
+			lcd.setBacklight(false)
+		}
+		gap = unit * 3
+	}
+
+	lcd.sleep(unit * 3)
+	lcd.setBacklight(was)
+}
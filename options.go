@@ -0,0 +1,102 @@
+package i2clcd
+
+import (
+	"machine"
+	"time"
+)
+
+// Option configures an I2CLCD created with New
+type Option func(*I2CLCD)
+
+// Default pulse timing. The HD44780 needs the enable line high for 450ns and
+// most commands finish within 37µs; Clear and Home wait longer on their own.
+const (
+	defaultEnableDelay  = 1 * time.Microsecond
+	defaultCommandDelay = 50 * time.Microsecond
+)
+
+// Create a new I2CLCD instance. Without options it drives a 16x2 display at
+// AddrPCF8574 using the DefaultPinMap.
+func New(bus *machine.I2C, opts ...Option) *I2CLCD {
+	lcd := &I2CLCD{
+		bus:          bus,
+		addr:         AddrPCF8574,
+		cols:         16,
+		rows:         2,
+		backlight:    true,
+		display:      true,
+		cursor:       false,
+		blink:        false,
+		sleep:        time.Sleep,
+		pins:         DefaultPinMap,
+		enableDelay:  defaultEnableDelay,
+		commandDelay: defaultCommandDelay,
+		maxTxChunk:   defaultTxChunk,
+	}
+	for _, opt := range opts {
+		opt(lcd)
+	}
+	lcd.setGeometry(lcd.cols, lcd.rows)
+	return lcd
+}
+
+// Set the I2C address of the backpack
+func WithAddress(addr uint8) Option {
+	return func(lcd *I2CLCD) {
+		lcd.addr = addr
+	}
+}
+
+// Set the display geometry
+func WithSize(cols, rows uint8) Option {
+	return func(lcd *I2CLCD) {
+		lcd.cols = cols
+		lcd.rows = rows
+	}
+}
+
+// Set which expander bits drive the control lines. A map that fails
+// PinMap.Valid is ignored and the DefaultPinMap kept.
+func WithPinMap(pins PinMap) Option {
+	return func(lcd *I2CLCD) {
+		if pins.Valid() {
+			lcd.pins = pins
+		}
+	}
+}
+
+// Set how long the enable line is held high and how long to wait after it
+// falls before the next nibble
+func WithTiming(enable, command time.Duration) Option {
+	return func(lcd *I2CLCD) {
+		lcd.enableDelay = enable
+		lcd.commandDelay = command
+	}
+}
+
+// Drive the backlight bit low to turn the backlight on, for backpacks with an
+// inverted backlight transistor
+func WithBacklightActiveLow() Option {
+	return func(lcd *I2CLCD) {
+		lcd.backlightActiveLow = true
+	}
+}
+
+// Configure a single-row display with split DDRAM, such as most 16x1
+// modules, where the left half of the row is the first DDRAM line and the
+// right half is the second. Cursor positioning and printing map columns to
+// the correct half.
+func WithSplitRow() Option {
+	return func(lcd *I2CLCD) {
+		lcd.split = true
+	}
+}
+
+// Mark expander pins, one bit each, that are wired as inputs rather than to
+// the LCD. They are written high on every transfer so ReadExpander can sample
+// them.
+func WithInputPins(mask byte) Option {
+	return func(lcd *I2CLCD) {
+		lcd.inputPins = mask
+	}
+}
@@ -0,0 +1,53 @@
+package i2clcd
+
+import (
+	"image"
+	"image/color"
+)
+
+// Render what the driver believes is on the glass as a grayscale image, 5x8
+// pixels per cell with lit pixels black on white. Custom characters are drawn
+// exactly from the glyphs last loaded; ROM characters are approximated with
+// the built-in 5x7 font, lowercase as uppercase, and characters it lacks are
+// drawn as an outlined box.
+func (lcd *I2CLCD) Snapshot() image.Image {
+	img := image.NewGray(image.Rect(0, 0, int(lcd.cols)*5, int(lcd.rows)*8))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	for row, line := range lcd.shadow {
+		for col, code := range line {
+			cell := lcd.cellPixels(code)
+			for y, bits := range cell {
+				for x := 0; x < 5; x++ {
+					if bits&(0x10>>x) != 0 {
+						img.SetGray(col*5+x, row*8+y, color.Gray{})
+					}
+				}
+			}
+		}
+	}
+	return img
+}
+
+// Return the 5x8 pixels of character code as Snapshot draws them
+func (lcd *I2CLCD) cellPixels(code byte) [8]byte {
+	switch {
+	case code < 0x10:
+		// Codes 8-15 mirror the eight custom characters
+		return lcd.glyphCache[code&0x07]
+	case code == fullBlock:
+		return [8]byte{0x1F, 0x1F, 0x1F, 0x1F, 0x1F, 0x1F, 0x1F, 0x1F}
+	}
+	c := code
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	var cell [8]byte
+	glyph, ok := font5x7[c]
+	if !ok {
+		return [8]byte{0x1F, 0x11, 0x11, 0x11, 0x11, 0x11, 0x1F, 0x00}
+	}
+	copy(cell[:], glyph[:])
+	return cell
+}
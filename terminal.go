@@ -0,0 +1,94 @@
+package i2clcd
+
+// WrapMode selects what Print and Write do when output reaches the end of a
+// row
+type WrapMode uint8
+
+const (
+	// WrapNone drops characters past the end of the row
+	WrapNone WrapMode = iota
+	// WrapLine continues on the next row, dropping characters past the end
+	// of the last row
+	WrapLine
+	// WrapScroll behaves like a terminal: output continues on the next row,
+	// '\n' and '\r' are honoured and the screen scrolls up a line when
+	// output runs past the bottom row
+	WrapScroll
+)
+
+// Set how Print and Write handle output that reaches the end of a row. The
+// default is WrapNone. Selecting WrapScroll clears the display; its content
+// is kept in a row buffer and repainted on scroll, and the hardware display
+// shift is not used.
+func (lcd *I2CLCD) SetWrapMode(mode WrapMode) {
+	lcd.wrap = mode
+	if mode != WrapScroll {
+		lcd.lines = nil
+		return
+	}
+
+	lcd.lines = newScreen(lcd.cols, lcd.rows)
+	lcd.Clear()
+}
+
+// Enable or disable terminal mode, the same as SetWrapMode with WrapScroll
+// or WrapNone
+func (lcd *I2CLCD) TerminalMode(on bool) {
+	if on {
+		lcd.SetWrapMode(WrapScroll)
+	} else {
+		lcd.SetWrapMode(WrapNone)
+	}
+}
+
+// Handle one byte of terminal output
+func (lcd *I2CLCD) terminalPut(b byte) error {
+	if lcd.noArea() {
+		return nil
+	}
+	switch b {
+	case '\n':
+		return lcd.terminalNewline()
+	case '\r':
+		return lcd.SetCursor(0, lcd.curRow)
+	}
+
+	if lcd.curCol >= lcd.cols {
+		if err := lcd.terminalNewline(); err != nil {
+			return err
+		}
+	}
+	lcd.lines[lcd.curRow][lcd.curCol] = b
+	return lcd.writeChar(b)
+}
+
+// Move to the start of the next row, scrolling if already on the last one
+func (lcd *I2CLCD) terminalNewline() error {
+	if lcd.curRow+1 < lcd.rows {
+		return lcd.SetCursor(0, lcd.curRow+1)
+	}
+	return lcd.terminalScroll()
+}
+
+// Shift the row buffer up one line, blank the bottom row and repaint
+func (lcd *I2CLCD) terminalScroll() error {
+	last := len(lcd.lines) - 1
+	top := lcd.lines[0]
+	copy(lcd.lines, lcd.lines[1:])
+	for i := range top {
+		top[i] = ' '
+	}
+	lcd.lines[last] = top
+
+	for row, line := range lcd.lines {
+		if err := lcd.SetCursor(0, uint8(row)); err != nil {
+			return err
+		}
+		for _, c := range line {
+			if err := lcd.writeChar(c); err != nil {
+				return err
+			}
+		}
+	}
+	return lcd.SetCursor(0, uint8(last))
+}
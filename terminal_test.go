@@ -0,0 +1,17 @@
+package i2clcd
+
+import "testing"
+
+func TestWrapScrollAfterDetectGeometry(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.SetWrapMode(WrapScroll)
+	lcd.DetectGeometry(func() bool { return true })
+	if err := lcd.Print("a\nb\nc\nd"); err != nil {
+		t.Fatal(err)
+	}
+	for row, want := range []byte("abcd") {
+		if got := lcd.shadow[row][0]; got != want {
+			t.Errorf("row %d starts with %q, want %q", row, got, want)
+		}
+	}
+}
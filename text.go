@@ -0,0 +1,367 @@
+package i2clcd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Return how many display cells text occupies when printed. Each rune takes
+// one cell regardless of how many bytes it is encoded in, and runes dropped
+// by the transform or acted on as control characters, such as '\n', take
+// none. The result saturates at 255.
+func (lcd *I2CLCD) MeasureWidth(text string) uint8 {
+	n := 0
+	for _, r := range text {
+		r, ok := lcd.transformRune(r)
+		if !ok || r >= 0x08 && r < 0x20 && !lcd.rawControl {
+			continue
+		}
+		n++
+	}
+	if n > 0xFF {
+		return 0xFF
+	}
+	return uint8(n)
+}
+
+// Print text starting at (col, row), truncated at the end of the row. In
+// strict mode text that does not fit is rejected with ErrOutOfRange.
+func (lcd *I2CLCD) PrintAt(col, row uint8, text string) error {
+	room := 0
+	if col < lcd.cols {
+		room = int(lcd.cols - col)
+	}
+	if cellCount(text) > room {
+		if lcd.strict {
+			return ErrOutOfRange
+		}
+		text = truncateCells(text, room)
+	}
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	return lcd.Print(text)
+}
+
+// Print formatted text at the cursor, stopping at the first bus error
+func (lcd *I2CLCD) Printf(format string, args ...interface{}) error {
+	return lcd.Print(fmt.Sprintf(format, args...))
+}
+
+// Print formatted text starting at (col, row), truncated at the end of the
+// row like PrintAt
+func (lcd *I2CLCD) PrintAtf(col, row uint8, format string, args ...interface{}) error {
+	return lcd.PrintAt(col, row, fmt.Sprintf(format, args...))
+}
+
+// Print text centered on row, padding both sides with spaces so the whole row
+// is overwritten. Text longer than the display is truncated.
+func (lcd *I2CLCD) PrintCentered(row uint8, text string) {
+	text = truncateCells(text, int(lcd.cols))
+	n := cellCount(text)
+	left := (int(lcd.cols) - n) / 2
+	right := int(lcd.cols) - n - left
+
+	lcd.SetCursor(0, row)
+	lcd.Print(spaces(left) + text + spaces(right))
+}
+
+// Show a centered title and subtitle for d, then clear the display
+func (lcd *I2CLCD) Splash(title, subtitle string, d time.Duration) {
+	lcd.Clear()
+	lcd.PrintCentered(0, title)
+	if lcd.rows > 1 {
+		lcd.PrintCentered(1, subtitle)
+	}
+	lcd.sleep(d)
+	lcd.Clear()
+}
+
+// Word-wrap text within the rectangle at (col, row) of the given size. Lines
+// beyond height are dropped and each line is padded to width, so cells
+// outside the rectangle are never touched. Rows of the rectangle below the
+// display are skipped.
+func (lcd *I2CLCD) PrintInRect(col, row, width, height uint8, text string) {
+	if width == 0 {
+		return
+	}
+	lines := wrapText(text, int(width))
+	for i := 0; i < int(height) && int(row)+i < int(lcd.rows); i++ {
+		line := ""
+		if i < len(lines) {
+			line = lines[i]
+		}
+		lcd.SetCursor(col, row+uint8(i))
+		lcd.Print(fitText(line, int(width)))
+	}
+}
+
+// Draw a width x height box at (col, row) with '+' corners and '-' and '|'
+// edges from the character ROM, then word-wrap text inside it as
+// PrintInRect does. Boxes narrower or shorter than 3 cells have no interior
+// and are not drawn, and any part of the box below the display is skipped.
+func (lcd *I2CLCD) TextBox(col, row, width, height uint8, text string) {
+	if width < 3 || height < 3 || row >= lcd.rows {
+		return
+	}
+	edge := "+" + strings.Repeat("-", int(width)-2) + "+"
+	lcd.SetCursor(col, row)
+	lcd.Print(edge)
+	bottom := int(row) + int(height) - 1
+	for r := int(row) + 1; r < bottom && r < int(lcd.rows); r++ {
+		lcd.PutChar(col, uint8(r), '|')
+		lcd.PutChar(col+width-1, uint8(r), '|')
+	}
+	if bottom < int(lcd.rows) {
+		lcd.SetCursor(col, uint8(bottom))
+		lcd.Print(edge)
+	}
+	lcd.PrintInRect(col+1, row+1, width-2, height-2, text)
+}
+
+// Word-wrap text across the whole display, blanking rows it does not reach,
+// and call onRow after each row has been written
+func (lcd *I2CLCD) PrintBlockProgress(text string, onRow func(rowsDone, rowsTotal uint8)) {
+	if lcd.cols == 0 {
+		return
+	}
+	lines := wrapText(text, int(lcd.cols))
+	for row := uint8(0); row < lcd.rows; row++ {
+		line := ""
+		if int(row) < len(lines) {
+			line = lines[row]
+		}
+		lcd.SetCursor(0, row)
+		lcd.Print(fitText(line, int(lcd.cols)))
+		if onRow != nil {
+			onRow(row+1, lcd.rows)
+		}
+	}
+}
+
+// Print the same text, padded or truncated to the row width, on every row.
+// Handy for checking that each row is addressed correctly on a new build.
+func (lcd *I2CLCD) PrintAllRows(text string) {
+	line := fitText(text, int(lcd.cols))
+	for row := uint8(0); row < lcd.rows; row++ {
+		lcd.SetCursor(0, row)
+		lcd.Print(line)
+	}
+}
+
+// Pair is a named value shown by PrintPairs
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// Print one "key:value" pair per row from the top, truncated to the row
+// width, and blank any rows left over. Pairs beyond the last row are not
+// shown.
+func (lcd *I2CLCD) PrintPairs(pairs []Pair) {
+	for row := 0; row < int(lcd.rows); row++ {
+		line := ""
+		if row < len(pairs) {
+			line = pairs[row].Key + ":" + pairs[row].Value
+		}
+		lcd.SetCursor(0, uint8(row))
+		lcd.Print(fitText(line, int(lcd.cols)))
+	}
+}
+
+// Print the entries of kv like PrintPairs, sorted by key
+func (lcd *I2CLCD) PrintKV(kv map[string]string) {
+	pairs := make([]Pair, 0, len(kv))
+	for k, v := range kv {
+		pairs = append(pairs, Pair{k, v})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key < pairs[j].Key })
+	lcd.PrintPairs(pairs)
+}
+
+// Print text at the cursor, then blank the rest of the row it ends on so no
+// characters from earlier, longer output are left behind. Stops at the first
+// bus error.
+func (lcd *I2CLCD) PrintEOL(text string) error {
+	if err := lcd.Print(text); err != nil {
+		return err
+	}
+	if lcd.noArea() {
+		return nil
+	}
+	for lcd.curCol < lcd.cols {
+		if err := lcd.writeChar(' '); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Overwrite row with spaces and leave the cursor at its start
+func (lcd *I2CLCD) ClearLine(row uint8) {
+	if lcd.noArea() {
+		return
+	}
+	lcd.SetCursor(0, row)
+	lcd.Print(spaces(int(lcd.cols)))
+	lcd.SetCursor(0, row)
+}
+
+// Fill every cell of the display with b and return the cursor home
+func (lcd *I2CLCD) Fill(b byte) {
+	if lcd.noArea() {
+		return
+	}
+	for row := uint8(0); row < lcd.rows; row++ {
+		lcd.SetCursor(0, row)
+		for col := uint8(0); col < lcd.cols; col++ {
+			lcd.writeChar(b)
+		}
+	}
+	lcd.SetCursor(0, 0)
+}
+
+// Print fields on row in fixed-width columns, padding or truncating each to
+// its width. Fields without a width are ignored. Returns ErrOutOfRange,
+// drawing nothing, if the widths add up to more than the row.
+func (lcd *I2CLCD) PrintColumns(row uint8, fields []string, widths []uint8) error {
+	total := 0
+	for _, w := range widths {
+		total += int(w)
+	}
+	if total > int(lcd.cols) {
+		return ErrOutOfRange
+	}
+
+	var line strings.Builder
+	for i, w := range widths {
+		field := ""
+		if i < len(fields) {
+			field = fields[i]
+		}
+		line.WriteString(fitText(field, int(w)))
+	}
+	if err := lcd.SetCursor(0, row); err != nil {
+		return err
+	}
+	return lcd.Print(line.String())
+}
+
+// Print full at (col, row) if it fits in width cells, otherwise abbreviated,
+// truncated if it is still too long. The field is padded with spaces to
+// width so a longer previous value is fully overwritten.
+func (lcd *I2CLCD) PrintAdaptive(col, row, width uint8, full, abbreviated string) {
+	text := abbreviated
+	if cellCount(full) <= int(width) {
+		text = full
+	}
+	lcd.SetCursor(col, row)
+	lcd.Print(fitText(text, int(width)))
+}
+
+// Fill row with left starting at column 0, the divider character in the
+// middle column and right aligned to the last column, e.g. "21.5   /   22.0".
+// Each side is truncated to the space on its side of the divider.
+func (lcd *I2CLCD) PrintPair(row uint8, left, right string, divider byte) {
+	if lcd.cols == 0 {
+		return
+	}
+	mid := int(lcd.cols) / 2
+	width := int(lcd.cols) - mid - 1
+	right = truncateCells(right, width)
+
+	lcd.SetCursor(0, row)
+	lcd.Print(fitText(left, mid))
+	lcd.writeChar(divider)
+	lcd.Print(spaces(width-cellCount(right)) + right)
+}
+
+// Print text perYield characters at a time, calling yield between groups so
+// a single-threaded cooperative scheduler can service other work during a
+// long update. yield is not called after the last group. A perYield below 1
+// or a nil yield prints everything in one go. Not intended for use alongside
+// goroutines that also draw: nothing is locked across the yields.
+func (lcd *I2CLCD) PrintChunked(text string, perYield int, yield func()) {
+	n := 0
+	for _, r := range text {
+		if perYield > 0 && yield != nil && n == perYield {
+			yield()
+			n = 0
+		}
+		lcd.PrintRune(r)
+		n++
+	}
+}
+
+// Return how many cells text takes on the display, one per rune
+func cellCount(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// Return the first n cells of text, cutting on a rune boundary
+func truncateCells(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for i := range text {
+		if n == 0 {
+			return text[:i]
+		}
+		n--
+	}
+	return text
+}
+
+// Pad text with spaces or truncate it to exactly width cells
+func fitText(text string, width int) string {
+	n := cellCount(text)
+	if n >= width {
+		return truncateCells(text, width)
+	}
+	return text + spaces(width-n)
+}
+
+// Split text into lines of at most width cells, breaking at spaces where
+// possible and hard-splitting words longer than a line
+func wrapText(text string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for cellCount(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			head := truncateCells(word, width)
+			lines = append(lines, head)
+			word = word[len(head):]
+		}
+		switch {
+		case line == "":
+			line = word
+		case cellCount(line)+1+cellCount(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Return a string of n spaces
+func spaces(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(" ", n)
+}
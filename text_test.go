@@ -0,0 +1,167 @@
+package i2clcd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintEOLBusError(t *testing.T) {
+	lcd, bus := newTestLCD(t, 16, 2)
+	bus.setFail(true)
+	done := make(chan error, 1)
+	go func() { done <- lcd.PrintEOL("hi") }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("PrintEOL with a failing bus returned nil")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PrintEOL did not return with a failing bus")
+	}
+}
+
+func TestBusErrorsPropagate(t *testing.T) {
+	glyph := [8]byte{0x1F}
+	tests := []struct {
+		name string
+		call func(lcd *I2CLCD) error
+	}{
+		{"CreateAndPrint", func(lcd *I2CLCD) error { return lcd.CreateAndPrint(0, glyph, 0, 0) }},
+		{"PrintAt", func(lcd *I2CLCD) error { return lcd.PrintAt(0, 0, "hi") }},
+		{"PrintAtf", func(lcd *I2CLCD) error { return lcd.PrintAtf(0, 0, "%d", 42) }},
+		{"Printf", func(lcd *I2CLCD) error { return lcd.Printf("%d", 42) }},
+		{"PrintBase", func(lcd *I2CLCD) error { return lcd.PrintBase(255, 16) }},
+		{"LoadBank", func(lcd *I2CLCD) error {
+			if err := lcd.DefineBank("b", [][8]byte{glyph}); err != nil {
+				t.Fatal(err)
+			}
+			return lcd.LoadBank("b")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lcd, bus := newTestLCD(t, 16, 2)
+			bus.setFail(true)
+			if err := tt.call(lcd); err == nil {
+				t.Fatal("got nil error with a failing bus")
+			}
+		})
+	}
+}
+
+func TestSendCommandsReturnsFirstError(t *testing.T) {
+	lcd, bus := newTestLCD(t, 16, 2)
+	// Fail the first command only; the second goes through
+	sent := 0
+	lcd.SetTrace(func(byte, bool) {
+		sent++
+		bus.setFail(sent == 1)
+	})
+	if err := lcd.SendCommands(LCD_CURSORSHIFT, LCD_CURSORSHIFT); err == nil {
+		t.Fatal("SendCommands masked the failure of its first command")
+	}
+}
+
+func TestTextMeasuredInCells(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.SetCharset(CharsetA00)
+	lcd.SetStrict(true)
+	if err := lcd.PrintAt(14, 0, "5°"); err != nil {
+		t.Fatalf("PrintAt of text that fits: %v", err)
+	}
+	if got := lcd.shadow[0][14:]; got[0] != '5' || got[1] != 0xDF {
+		t.Fatalf("cells 14-15 = % x, want 35 df", got)
+	}
+	if err := lcd.PrintAt(15, 0, "5°"); err != ErrOutOfRange {
+		t.Fatalf("PrintAt of text one cell too long = %v, want ErrOutOfRange", err)
+	}
+
+	lcd.SetStrict(false)
+	lcd.PrintAt(15, 0, "°5")
+	if got := lcd.shadow[0][15]; got != 0xDF {
+		t.Fatalf("truncated PrintAt left %#x in the last cell, want 0xdf", got)
+	}
+
+	lcd.PrintCentered(1, "°°°°°°°°")
+	want := "    " + strings.Repeat("\xDF", 8) + "    "
+	if got := string(lcd.shadow[1]); got != want {
+		t.Fatalf("PrintCentered row = %q, want %q", got, want)
+	}
+}
+
+func TestWrapTextRunes(t *testing.T) {
+	got := wrapText("°°°°° ab", 3)
+	want := []string{"°°°", "°°", "ab"}
+	if len(got) != len(want) {
+		t.Fatalf("wrapText = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wrapText = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestRectsClipAtBottomRow(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.PrintInRect(0, 1, 16, 3, "hello world")
+	if got, want := string(lcd.shadow[1]), fitText("hello world", 16); got != want {
+		t.Errorf("PrintInRect row 1 = %q, want %q", got, want)
+	}
+
+	lcd, _ = newTestLCD(t, 16, 2)
+	lcd.TextBox(0, 0, 8, 4, "hi")
+	if got, want := string(lcd.shadow[1][:8]), "|hi    |"; got != want {
+		t.Errorf("TextBox row 1 = %q, want %q", got, want)
+	}
+}
+
+func TestWriteMapsRunes(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	lcd.SetCharset(CharsetA00)
+	fmt.Fprintf(lcd, "5°")
+	if got, want := string(lcd.shadow[0][:3]), "5\xDF "; got != want {
+		t.Errorf("Fprintf(\"5°\") = %q, want %q", got, want)
+	}
+
+	// A rune split across writes prints once both halves arrive
+	lcd.Home()
+	deg := []byte("°")
+	lcd.Write(deg[:1])
+	lcd.Write(deg[1:])
+	if got := lcd.shadow[0][0]; got != 0xDF {
+		t.Errorf("split degree sign = %#x, want 0xdf", got)
+	}
+}
+
+func TestPrintColumnsWidths(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	if err := lcd.PrintColumns(0, []string{"a", "b"}, []uint8{10, 7}); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("17 columns on a 16-column row = %v, want ErrOutOfRange", err)
+	}
+	if got := string(lcd.shadow[0]); got != strings.Repeat(" ", 16) {
+		t.Errorf("rejected columns drew %q", got)
+	}
+	if err := lcd.PrintColumns(0, []string{"temp", "21.5"}, []uint8{10, 6}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(lcd.shadow[0]), "temp      21.5  "; got != want {
+		t.Errorf("row = %q, want %q", got, want)
+	}
+}
+
+func TestMeasureWidthSkipsControls(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	for text, want := range map[string]uint8{"a\nb": 2, "a\r\tb": 2, "°C": 2, "\x01x": 2} {
+		if got := lcd.MeasureWidth(text); got != want {
+			t.Errorf("MeasureWidth(%q) = %d, want %d", text, got, want)
+		}
+	}
+	lcd.SetRawControl(true)
+	if got := lcd.MeasureWidth("a\nb"); got != 3 {
+		t.Errorf("MeasureWidth(\"a\\nb\") with raw control = %d, want 3", got)
+	}
+}
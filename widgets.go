@@ -0,0 +1,776 @@
+package i2clcd
+
+import (
+	"math"
+	"strconv"
+)
+
+// Frames cycled by the spinner helpers. Plain ROM characters are used so the
+// spinner renders on every panel without spending a CGRAM slot.
+var spinnerFrames = []byte{'.', 'o', 'O', 'o'}
+
+// ROM code for a solid 5x8 block
+const fullBlock = 0xFF
+
+// A00 ROM codes for arrows and a centred dot
+const (
+	romArrowRight = 0x7E
+	romArrowLeft  = 0x7F
+	romMiddleDot  = 0xA5
+)
+
+// Print a solid block from the character ROM at the cursor
+func (lcd *I2CLCD) PrintBlock() {
+	lcd.writeChar(fullBlock)
+}
+
+// Print a right arrow from the A00 character ROM at the cursor
+func (lcd *I2CLCD) PrintArrowRight() {
+	lcd.writeChar(romArrowRight)
+}
+
+// Print a left arrow from the A00 character ROM at the cursor
+func (lcd *I2CLCD) PrintArrowLeft() {
+	lcd.writeChar(romArrowLeft)
+}
+
+// Print a centred dot from the A00 character ROM at the cursor
+func (lcd *I2CLCD) PrintMiddleDot() {
+	lcd.writeChar(romMiddleDot)
+}
+
+// Print a degree sign from the character ROM at the cursor
+func (lcd *I2CLCD) PrintDegree() {
+	lcd.writeChar(lcd.degreeCode())
+}
+
+// Partial-fill glyphs for horizontal bars, one to four columns lit from the
+// left. Empty and full cells use the ROM space and solid block instead.
+var barGlyphs = [4][8]byte{
+	{0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10},
+	{0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18},
+	{0x1C, 0x1C, 0x1C, 0x1C, 0x1C, 0x1C, 0x1C, 0x1C},
+	{0x1E, 0x1E, 0x1E, 0x1E, 0x1E, 0x1E, 0x1E, 0x1E},
+}
+
+// Draw the next spinner frame at (col, row) followed by a right-aligned percentage
+func (lcd *I2CLCD) Working(col, row uint8, percent uint8) {
+	lcd.SetCursor(col, row)
+	lcd.writeChar(spinnerFrames[lcd.spinnerFrame])
+	lcd.Print(percentText(percent))
+	lcd.spinnerFrame = (lcd.spinnerFrame + 1) % uint8(len(spinnerFrames))
+}
+
+// Draw a bar across the full width of row filled to percent. Values above
+// 100 draw a full bar. The bar glyphs take four CGRAM locations.
+func (lcd *I2CLCD) ProgressBar(row uint8, percent uint8) error {
+	if lcd.cols == 0 {
+		return nil
+	}
+	if err := lcd.ensureBarGlyphs(); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(0, row); err != nil {
+		return err
+	}
+	return lcd.drawBar(int(lcd.cols), percent)
+}
+
+// Print a label, a bracketed bar filling the rest of row and the percentage,
+// e.g. "BAT [####   ] 60%". The bar shares ProgressBar's glyphs.
+func (lcd *I2CLCD) Gauge(row uint8, label string, percent uint8) error {
+	if label != "" {
+		label += " "
+	}
+	pct := percentText(percent)
+	width := int(lcd.cols) - cellCount(label) - len(pct) - 2
+
+	if width < 1 {
+		// No room for a bar; show what fits of the text alone
+		return lcd.PrintAt(0, row, fitText(label+pct, int(lcd.cols)))
+	}
+
+	if err := lcd.ensureBarGlyphs(); err != nil {
+		return err
+	}
+	if err := lcd.PrintAt(0, row, label); err != nil {
+		return err
+	}
+	if err := lcd.writeChar('['); err != nil {
+		return err
+	}
+	if err := lcd.drawBar(width, percent); err != nil {
+		return err
+	}
+	if err := lcd.writeChar(']'); err != nil {
+		return err
+	}
+	return lcd.Print(pct)
+}
+
+// Fill row with a bar sized to percent followed by the percentage in the
+// last four columns, e.g. "#######     60%". Displays too narrow for a bar
+// show only the percentage. The bar shares ProgressBar's glyphs.
+func (lcd *I2CLCD) PercentLine(row uint8, percent uint8) error {
+	pct := percentText(percent)
+	width := int(lcd.cols) - len(pct)
+	if width < 1 {
+		return lcd.PrintAt(0, row, fitText(pct, int(lcd.cols)))
+	}
+
+	if err := lcd.ensureBarGlyphs(); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(0, row); err != nil {
+		return err
+	}
+	if err := lcd.drawBar(width, percent); err != nil {
+		return err
+	}
+	return lcd.Print(pct)
+}
+
+// Allocate four CGRAM locations to the partial-fill bar glyphs and load them
+// unless they are already there. The glyphs are loaded again after an Init,
+// a bus error part way through loading or anything else overwriting them.
+// Returns ErrGlyphInUse if four locations cannot be found.
+func (lcd *I2CLCD) ensureBarGlyphs() error {
+	slots, err := lcd.allocGlyphs(ownerBars, len(barGlyphs))
+	if err != nil {
+		return err
+	}
+	var want [4]byte
+	copy(want[:], slots)
+	if lcd.barGlyphsLoaded && want == lcd.barSlots && lcd.barGlyphsIntact() {
+		return nil
+	}
+	lcd.barGlyphsLoaded = false
+	lcd.barSlots = want
+	if err := lcd.loadGlyphSet(slots, barGlyphs[:]...); err != nil {
+		return err
+	}
+	if err := lcd.moveCursor(lcd.curCol, lcd.curRow); err != nil {
+		return err
+	}
+	lcd.barGlyphsLoaded = true
+	return nil
+}
+
+// Report whether barSlots still hold the bar glyphs
+func (lcd *I2CLCD) barGlyphsIntact() bool {
+	for i, glyph := range barGlyphs {
+		if lcd.glyphCache[lcd.barSlots[i]] != glyph {
+			return false
+		}
+	}
+	return true
+}
+
+// Load the bar glyphs shared by ProgressBar, Gauge and PercentLine now and
+// return the CGRAM locations they occupy, one to four columns lit in order.
+// Returns ErrGlyphInUse if four locations cannot be found.
+func (lcd *I2CLCD) ReserveBarGlyphs() ([]byte, error) {
+	if err := lcd.ensureBarGlyphs(); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), lcd.barSlots[:]...), nil
+}
+
+// Draw a width-cell horizontal bar at the cursor filled to percent
+func (lcd *I2CLCD) drawBar(width int, percent uint8) error {
+	if percent > 100 {
+		percent = 100
+	}
+	filled := width * 5 * int(percent) / 100
+	for i := 0; i < width; i++ {
+		if err := lcd.writeChar(lcd.barCell(filled - i*5)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return the character for a bar cell with n of its five pixel columns lit
+func (lcd *I2CLCD) barCell(n int) byte {
+	switch {
+	case n >= 5:
+		return fullBlock
+	case n <= 0:
+		return ' '
+	}
+	return lcd.barSlots[n-1]
+}
+
+// Draw a meter across the full width of row filled to level percent, with a
+// one-pixel vertical peak-hold marker at peak percent. A peak of 0 draws no
+// marker. The bar shares ProgressBar's glyphs and the cell holding the marker
+// takes one more CGRAM location.
+func (lcd *I2CLCD) LevelMeter(row uint8, level, peak uint8) error {
+	if lcd.cols == 0 {
+		return nil
+	}
+	if err := lcd.ensureBarGlyphs(); err != nil {
+		return err
+	}
+	slots, err := lcd.allocGlyphs(ownerPeak, 1)
+	if err != nil {
+		return err
+	}
+	peakSlot := slots[0]
+	if level > 100 {
+		level = 100
+	}
+	if peak > 100 {
+		peak = 100
+	}
+
+	width := int(lcd.cols)
+	filled := width * 5 * int(level) / 100
+	peakCell := -1
+	if peak > 0 {
+		// Round up so any non-zero peak lands on a pixel
+		px := (width*5*int(peak)+99)/100 - 1
+		if px < 0 {
+			px = 0
+		}
+		peakCell = px / 5
+
+		// The marker cell keeps whatever part of the bar it covers
+		var glyph [8]byte
+		lit := filled - peakCell*5
+		for r := range glyph {
+			for c := 0; c < 5 && c < lit; c++ {
+				glyph[r] |= 0x10 >> c
+			}
+			glyph[r] |= 0x10 >> (px % 5)
+		}
+		if err := lcd.loadGlyph(peakSlot, glyph[:]); err != nil {
+			return err
+		}
+	}
+
+	if err := lcd.SetCursor(0, row); err != nil {
+		return err
+	}
+	for i := 0; i < width; i++ {
+		code := lcd.barCell(filled - i*5)
+		if i == peakCell {
+			code = peakSlot
+		}
+		if err := lcd.writeChar(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Format percent (clamped to 100) right-aligned in four cells, e.g. " 60%"
+func percentText(percent uint8) string {
+	if percent > 100 {
+		percent = 100
+	}
+	text := strconv.Itoa(int(percent)) + "%"
+	return spaces(4-len(text)) + text
+}
+
+// Draw a width-cell sparkline of values at (col, row). Values are scaled
+// between their minimum and maximum onto eight bar heights; when there are
+// more values than cells the most recent ones are shown. Needs all eight
+// CGRAM locations, returning ErrGlyphInUse if any holds another glyph.
+func (lcd *I2CLCD) Sparkline(col, row uint8, values []int, width uint8) error {
+	if len(values) > int(width) {
+		values = values[len(values)-int(width):]
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	slots, err := lcd.allocGlyphs(ownerSparkline, 8)
+	if err != nil {
+		return err
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+
+	for level := 1; level <= 8; level++ {
+		var glyph [8]byte
+		for i := 8 - level; i < 8; i++ {
+			glyph[i] = 0x1F
+		}
+		if err := lcd.loadGlyph(slots[level-1], glyph[:]); err != nil {
+			return err
+		}
+	}
+
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	if err := lcd.Print(spaces(int(width) - len(values))); err != nil {
+		return err
+	}
+	for _, v := range values {
+		level := 4
+		if hi > lo {
+			level = (v - lo) * 8 / (hi - lo)
+		}
+		code := byte(' ')
+		if level > 0 {
+			code = slots[level-1]
+		}
+		if err := lcd.writeChar(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Print text at (col, row) in reverse video by loading an inverted glyph for
+// each distinct character into CGRAM. Only characters in the built-in 5x7
+// font are supported, with lowercase letters shown as uppercase. Each
+// distinct character takes a free CGRAM location. Returns ErrTooManyGlyphs
+// for more than 8 distinct characters, or ErrGlyphInUse if too few locations
+// are free.
+func (lcd *I2CLCD) PrintInverse(col, row uint8, text string) error {
+	// Number the characters before touching CGRAM so a rejected string
+	// leaves it intact
+	index := make(map[byte]int)
+	var order []byte
+	codes := make([]byte, len(text))
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if _, ok := font5x7[c]; !ok {
+			return ErrUnsupportedChar
+		}
+		if _, ok := index[c]; !ok {
+			index[c] = len(order)
+			order = append(order, c)
+		}
+		codes[i] = c
+	}
+	if len(order) > len(lcd.glyphOwners) {
+		return ErrTooManyGlyphs
+	}
+	slots, err := lcd.allocGlyphs(ownerInverse, len(order))
+	if err != nil {
+		return err
+	}
+
+	for i, c := range order {
+		var inverted [8]byte
+		for r, bits := range font5x7[c] {
+			inverted[r] = ^bits & 0x1F
+		}
+		inverted[7] = 0x1F
+		if err := lcd.loadGlyph(slots[i], inverted[:]); err != nil {
+			return err
+		}
+	}
+
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	for i, c := range codes {
+		codes[i] = slots[index[c]]
+	}
+	return lcd.writeChars(codes...)
+}
+
+// Arrow glyphs for the eight compass directions, clockwise from north
+var arrowGlyphs = [8][8]byte{
+	{0x04, 0x0E, 0x15, 0x04, 0x04, 0x04, 0x04, 0x00}, // N
+	{0x00, 0x07, 0x03, 0x05, 0x08, 0x10, 0x00, 0x00}, // NE
+	{0x00, 0x04, 0x02, 0x1F, 0x02, 0x04, 0x00, 0x00}, // E
+	{0x00, 0x10, 0x08, 0x05, 0x03, 0x07, 0x00, 0x00}, // SE
+	{0x04, 0x04, 0x04, 0x04, 0x15, 0x0E, 0x04, 0x00}, // S
+	{0x00, 0x01, 0x02, 0x14, 0x18, 0x1C, 0x00, 0x00}, // SW
+	{0x00, 0x04, 0x08, 0x1F, 0x08, 0x04, 0x00, 0x00}, // W
+	{0x00, 0x1C, 0x18, 0x14, 0x02, 0x01, 0x00, 0x00}, // NW
+}
+
+// Print a compass heading at (col, row) as an arrow pointing towards the
+// nearest of the eight compass directions, then the whole degrees
+// right-aligned in four cells and a degree sign. Only the arrow in use is
+// loaded, taking one CGRAM location. Returns ErrOutOfRange if the six cells
+// do not fit on the display at (col, row).
+func (lcd *I2CLCD) PrintHeading(col, row uint8, degrees float64) error {
+	if int(col)+6 > int(lcd.cols) || row >= lcd.rows {
+		return ErrOutOfRange
+	}
+	slots, err := lcd.allocGlyphs(ownerHeading, 1)
+	if err != nil {
+		return err
+	}
+	arrowSlot := slots[0]
+	whole := int(math.Round(degrees)) % 360
+	if whole < 0 {
+		whole += 360
+	}
+
+	dir := (whole*2 + 45) / 90 % 8
+	if err := lcd.loadGlyph(arrowSlot, arrowGlyphs[dir][:]); err != nil {
+		return err
+	}
+
+	text := strconv.Itoa(whole)
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	if err := lcd.writeChar(arrowSlot); err != nil {
+		return err
+	}
+	if err := lcd.Print(spaces(4-len(text)) + text); err != nil {
+		return err
+	}
+	return lcd.writeChar(lcd.degreeCode())
+}
+
+// Draw an arrow at (col, row) pointing like an hour hand at hour, 12 straight
+// up and 3 to the right, rounded to the nearest of the eight compass arrows.
+// Hours outside 0-23 wrap around the dial. The arrow takes one CGRAM
+// location.
+func (lcd *I2CLCD) ClockHand(col, row uint8, hour int) error {
+	slots, err := lcd.allocGlyphs(ownerClockHand, 1)
+	if err != nil {
+		return err
+	}
+	arrowSlot := slots[0]
+	degrees := hour % 12 * 30
+	if degrees < 0 {
+		degrees += 360
+	}
+	if err := lcd.loadGlyph(arrowSlot, arrowGlyphs[(degrees*2+45)/90%8][:]); err != nil {
+		return err
+	}
+	return lcd.PutChar(col, row, arrowSlot)
+}
+
+// A filled circle for the toggle switch knob
+var knobGlyph = [8]byte{0x00, 0x0E, 0x1F, 0x1F, 0x1F, 0x0E, 0x00, 0x00}
+
+// Draw a four-cell slider switch at (col, row) with the knob on the right
+// when on and on the left when off, e.g. "[-●]". The knob takes one CGRAM
+// location.
+func (lcd *I2CLCD) ToggleSwitch(col, row uint8, on bool) error {
+	slots, err := lcd.allocGlyphs(ownerKnob, 1)
+	if err != nil {
+		return err
+	}
+	knobSlot := slots[0]
+	if err := lcd.loadGlyph(knobSlot, knobGlyph[:]); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	if on {
+		return lcd.writeChars('[', '-', knobSlot, ']')
+	}
+	return lcd.writeChars('[', knobSlot, '-', ']')
+}
+
+// Three dots along the baseline; neither character ROM has an ellipsis
+var ellipsisGlyph = [8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x15, 0x00}
+
+// Print text in a width-cell field at (col, row). Text that fits is padded
+// with spaces; longer text is cut and its last visible cell replaced with an
+// ellipsis, which takes one CGRAM location.
+func (lcd *I2CLCD) PrintEllipsis(col, row, width uint8, text string) error {
+	if width == 0 {
+		return nil
+	}
+	if cellCount(text) <= int(width) {
+		if err := lcd.SetCursor(col, row); err != nil {
+			return err
+		}
+		return lcd.Print(fitText(text, int(width)))
+	}
+
+	slots, err := lcd.allocGlyphs(ownerEllipsis, 1)
+	if err != nil {
+		return err
+	}
+	ellipsisSlot := slots[0]
+	if err := lcd.loadGlyph(ellipsisSlot, ellipsisGlyph[:]); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	if err := lcd.Print(truncateCells(text, int(width)-1)); err != nil {
+		return err
+	}
+	return lcd.writeChar(ellipsisSlot)
+}
+
+// A line along the top pixel row, sitting just under the character above
+var underlineGlyph = [8]byte{0x1F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+// Print text at (col, row) and underline it by drawing a line glyph in the
+// cells of the row below, which is overwritten. Needs a row below, so the
+// last row returns ErrOutOfRange. The line takes one CGRAM location.
+func (lcd *I2CLCD) PrintUnderlined(col, row uint8, text string) error {
+	if row+1 >= lcd.rows {
+		return ErrOutOfRange
+	}
+	slots, err := lcd.allocGlyphs(ownerUnderline, 1)
+	if err != nil {
+		return err
+	}
+	underlineSlot := slots[0]
+	if err := lcd.loadGlyph(underlineSlot, underlineGlyph[:]); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	if err := lcd.Print(text); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(col, row+1); err != nil {
+		return err
+	}
+	for i := lcd.MeasureWidth(text); i > 0; i-- {
+		if err := lcd.printByte(underlineSlot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Segment glyphs for PrintSevenSeg
+var sevenSegGlyphs = [5][8]byte{
+	{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03}, // left verticals (f, e)
+	{0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18}, // right verticals (b, c)
+	{0x1F, 0x1F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // top or middle (a, g)
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F, 0x1F}, // bottom (d)
+	{0x1F, 0x1F, 0x00, 0x00, 0x00, 0x00, 0x1F, 0x1F}, // middle and bottom (g, d)
+}
+
+// Lit segments for each digit, bit 0 for segment a through bit 6 for g
+var sevenSegDigits = [10]byte{0x3F, 0x06, 0x5B, 0x4F, 0x66, 0x6D, 0x7D, 0x07, 0x7F, 0x6F}
+
+// Draw digit as a seven-segment numeral three cells wide on rows 0 and 1,
+// starting at col. The vertical segments occupy the outer columns and the
+// horizontal ones the middle column. All ten digits share five glyphs,
+// taking five CGRAM locations, so any number of seven-segment digits can be
+// on screen together. Values above 9 draw a blank digit.
+func (lcd *I2CLCD) PrintSevenSeg(col uint8, digit uint8) error {
+	slots, err := lcd.allocGlyphs(ownerSevenSeg, len(sevenSegGlyphs))
+	if err != nil {
+		return err
+	}
+	const (
+		segA = 1 << iota
+		segB
+		segC
+		segD
+		segE
+		segF
+		segG
+	)
+	var segs byte
+	if digit < 10 {
+		segs = sevenSegDigits[digit]
+	}
+	pick := func(seg byte, glyph int) byte {
+		if segs&seg != 0 {
+			return slots[glyph]
+		}
+		return ' '
+	}
+
+	if err := lcd.loadGlyphSet(slots, sevenSegGlyphs[:]...); err != nil {
+		return err
+	}
+
+	bottom := byte(' ')
+	switch {
+	case segs&segG != 0 && segs&segD != 0:
+		bottom = slots[4]
+	case segs&segG != 0:
+		bottom = slots[2]
+	case segs&segD != 0:
+		bottom = slots[3]
+	}
+
+	if err := lcd.SetCursor(col, 0); err != nil {
+		return err
+	}
+	if err := lcd.writeChars(pick(segF, 0), pick(segA, 2), pick(segB, 1)); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(col, 1); err != nil {
+		return err
+	}
+	return lcd.writeChars(pick(segE, 0), bottom, pick(segC, 1))
+}
+
+// A filled and a hollow square for set and clear bits
+var bitGlyphs = [2][8]byte{
+	{0x00, 0x1F, 0x1F, 0x1F, 0x1F, 0x1F, 0x00, 0x00},
+	{0x00, 0x1F, 0x11, 0x11, 0x11, 0x1F, 0x00, 0x00},
+}
+
+// Show the low width bits of value as a row of LEDs starting at (col, row),
+// most significant bit on the left, so bit width-1 is in the first cell and
+// bit 0 in the last. Set bits are drawn filled and clear bits hollow. Widths
+// above 32 are treated as 32. The two glyphs take two CGRAM locations.
+func (lcd *I2CLCD) PrintBits(col, row uint8, value uint32, width uint8) error {
+	if width > 32 {
+		width = 32
+	}
+	if width == 0 {
+		return nil
+	}
+	slots, err := lcd.allocGlyphs(ownerBits, len(bitGlyphs))
+	if err != nil {
+		return err
+	}
+	bitOnSlot, bitOffSlot := slots[0], slots[1]
+	if err := lcd.loadGlyphSet(slots, bitGlyphs[:]...); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	for i := int(width) - 1; i >= 0; i-- {
+		code := bitOffSlot
+		if value&(1<<uint(i)) != 0 {
+			code = bitOnSlot
+		}
+		if err := lcd.writeChar(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Interior fill, in pixel columns out of seven, for the empty, low, mid,
+// high and full battery levels
+var batteryFill = [5]int{0, 1, 3, 5, 7}
+
+// Draw a two-cell battery at (col, row) filled to percent, followed by the
+// percentage right-aligned in four cells, e.g. "[██  ] 60%". The fill is
+// rounded to one of five levels from empty to full. The battery halves take
+// two CGRAM locations.
+func (lcd *I2CLCD) BatteryWidget(col, row uint8, percent uint8) error {
+	slots, err := lcd.allocGlyphs(ownerBattery, 2)
+	if err != nil {
+		return err
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	left, right := batteryGlyphs(batteryFill[(int(percent)+12)/25])
+	if err := lcd.loadGlyphSet(slots, left, right); err != nil {
+		return err
+	}
+	if err := lcd.SetCursor(col, row); err != nil {
+		return err
+	}
+	if err := lcd.writeChars(slots[0], slots[1]); err != nil {
+		return err
+	}
+	return lcd.Print(percentText(percent))
+}
+
+// Build the left and right battery glyphs with fill of the seven interior
+// pixel columns lit. The left cell holds the outline's left edge and four
+// interior columns; the right cell the other three, the right edge and the
+// terminal nub.
+func batteryGlyphs(fill int) (left, right [8]byte) {
+	var inLeft, inRight byte
+	for i := 0; i < fill; i++ {
+		if i < 4 {
+			inLeft |= 0x08 >> i
+		} else {
+			inRight |= 0x10 >> (i - 4)
+		}
+	}
+	left = [8]byte{0x00, 0x1F, 0x10 | inLeft, 0x10 | inLeft, 0x10 | inLeft, 0x10 | inLeft, 0x1F, 0x00}
+	right = [8]byte{0x00, 0x1E, 0x02 | inRight, 0x03 | inRight, 0x03 | inRight, 0x02 | inRight, 0x1E, 0x00}
+	return left, right
+}
+
+// Draw a checkbox list item filling row: a '>' selection marker in column 0
+// when selected, then "[x]" or "[ ]", a space and the label padded or
+// truncated to the rest of the row
+func (lcd *I2CLCD) ListItem(row uint8, label string, checked, selected bool) {
+	mark := byte(' ')
+	if checked {
+		mark = 'x'
+	}
+	lcd.listItem(row, label, '[', mark, ']', selected)
+}
+
+// Draw a radio-button list item like ListItem, with "(•)" or "( )" in place
+// of the checkbox. The dot is the A00 ROM's centred dot.
+func (lcd *I2CLCD) RadioItem(row uint8, label string, checked, selected bool) {
+	mark := byte(' ')
+	if checked {
+		mark = romMiddleDot
+	}
+	lcd.listItem(row, label, '(', mark, ')', selected)
+}
+
+// Draw the marker, the three-cell indicator and the label across row
+func (lcd *I2CLCD) listItem(row uint8, label string, open, mark, close byte, selected bool) {
+	if lcd.cols < 5 {
+		return
+	}
+	cursor := byte(' ')
+	if selected {
+		cursor = '>'
+	}
+	lcd.SetCursor(0, row)
+	lcd.writeChar(cursor)
+	lcd.writeChar(open)
+	lcd.writeChar(mark)
+	lcd.writeChar(close)
+	lcd.writeChar(' ')
+	lcd.Print(fitText(label, int(lcd.cols)-5))
+}
+
+// Show a confirmation dialog: message word-wrapped and centred on every row
+// but the last, and "[OK]" and "[Cancel]" buttons on the bottom row with OK
+// selected. The display is cleared first. Buttons are not read here; move
+// the selection with ConfirmSelect as the user presses them.
+func (lcd *I2CLCD) Confirm(message string) {
+	lcd.Clear()
+	if lcd.noArea() {
+		return
+	}
+	lines := wrapText(message, int(lcd.cols))
+	for i, line := range lines {
+		if i >= int(lcd.rows)-1 {
+			break
+		}
+		lcd.PrintCentered(uint8(i), line)
+	}
+	lcd.ConfirmSelect(false)
+}
+
+// Redraw the bottom row of a dialog shown by Confirm with a '>' marking
+// Cancel when right is true and OK otherwise
+func (lcd *I2CLCD) ConfirmSelect(right bool) {
+	if lcd.noArea() {
+		return
+	}
+	okMark, cancelMark := ">", " "
+	if right {
+		okMark, cancelMark = " ", ">"
+	}
+	line := okMark + "[OK]" + spaces(int(lcd.cols)-14) + cancelMark + "[Cancel]"
+	lcd.SetCursor(0, lcd.rows-1)
+	lcd.Print(fitText(line, int(lcd.cols)))
+}
@@ -0,0 +1,169 @@
+package i2clcd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLevelMeterPeakSweep(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	width := int(lcd.cols)
+	for _, level := range []uint8{0, 50, 100} {
+		for peak := 0; peak <= 100; peak++ {
+			if err := lcd.LevelMeter(0, level, uint8(peak)); err != nil {
+				t.Fatalf("LevelMeter(%d, %d): %v", level, peak, err)
+			}
+			if peak == 0 {
+				continue
+			}
+			px := (width*5*peak+99)/100 - 1
+			code := lcd.shadow[0][px/5]
+			if code > 7 {
+				t.Fatalf("level %d peak %d: cell %d holds %#x, want a custom glyph", level, peak, px/5, code)
+			}
+			for r, bits := range lcd.glyphCache[code] {
+				if bits&(0x10>>(px%5)) == 0 {
+					t.Fatalf("level %d peak %d: glyph row %d = %#x misses the marker pixel", level, peak, r, bits)
+				}
+			}
+		}
+	}
+}
+
+func TestBarGlyphsReloadAfterBusError(t *testing.T) {
+	lcd, bus := newTestLCD(t, 16, 2)
+	bus.setFail(true)
+	if err := lcd.ProgressBar(0, 50); err == nil {
+		t.Fatal("ProgressBar with a failing bus returned nil")
+	}
+	if lcd.barGlyphsLoaded {
+		t.Fatal("bar glyphs marked loaded after a failed load")
+	}
+	bus.setFail(false)
+	if err := lcd.ProgressBar(0, 50); err != nil {
+		t.Fatal(err)
+	}
+	if !lcd.barGlyphsLoaded {
+		t.Fatal("bar glyphs not loaded once the bus recovered")
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	fill := func(b byte, n int) []byte { return bytes.Repeat([]byte{b}, n) }
+	blank := fill(' ', 16)
+	solid := fill(fullBlock, 16)
+	tests := []struct {
+		percent uint8
+		want    func(slots []byte) []byte
+	}{
+		{0, func([]byte) []byte { return blank }},
+		{33, func(s []byte) []byte { return bytes.Join([][]byte{solid[:5], {s[0]}, blank[:10]}, nil) }},
+		{50, func([]byte) []byte { return bytes.Join([][]byte{solid[:8], blank[:8]}, nil) }},
+		{100, func([]byte) []byte { return solid }},
+		{150, func([]byte) []byte { return solid }},
+	}
+	for _, tt := range tests {
+		lcd, _ := newTestLCD(t, 16, 2)
+		if err := lcd.ProgressBar(1, tt.percent); err != nil {
+			t.Fatalf("ProgressBar(%d): %v", tt.percent, err)
+		}
+		slots := lcd.glyphSlots(ownerBars)
+		if len(slots) != len(barGlyphs) {
+			t.Fatalf("%d%%: bars hold locations %v", tt.percent, slots)
+		}
+		for i, slot := range slots {
+			if lcd.glyphCache[slot] != barGlyphs[i] {
+				t.Errorf("%d%%: location %d = % x, want % x", tt.percent, slot, lcd.glyphCache[slot], barGlyphs[i])
+			}
+		}
+		want := tt.want(slots)
+		if !bytes.Equal(lcd.shadow[1], want) || !bytes.Equal(lcd.frame[1], want) {
+			t.Errorf("%d%%: row = % x, frame % x, want % x", tt.percent, lcd.shadow[1], lcd.frame[1], want)
+		}
+		if !bytes.Equal(lcd.shadow[0], blank) {
+			t.Errorf("%d%%: row 0 changed to %q", tt.percent, lcd.shadow[0])
+		}
+	}
+}
+
+func TestPrintHeadingNeedsSixCells(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	if err := lcd.PrintHeading(11, 0, 90); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("PrintHeading at col 11 = %v, want ErrOutOfRange", err)
+	}
+	if err := lcd.PrintHeading(0, 2, 90); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("PrintHeading below the last row = %v, want ErrOutOfRange", err)
+	}
+	if err := lcd.PrintHeading(10, 1, 90); err != nil {
+		t.Fatal(err)
+	}
+	if got := lcd.shadow[1][11:]; string(got[:4]) != "  90" || got[4] != lcd.degreeCode() {
+		t.Errorf("heading cells = % x, want \"  90\" and a degree sign", got)
+	}
+}
+
+func TestWidgetBusErrorsPropagate(t *testing.T) {
+	bmp := [][]bool{{true, false, true}}
+	tests := []struct {
+		name string
+		call func(lcd *I2CLCD) error
+	}{
+		{"ProgressBar", func(lcd *I2CLCD) error { return lcd.ProgressBar(0, 50) }},
+		{"Gauge", func(lcd *I2CLCD) error { return lcd.Gauge(0, "BAT", 50) }},
+		{"PercentLine", func(lcd *I2CLCD) error { return lcd.PercentLine(0, 50) }},
+		{"LevelMeter", func(lcd *I2CLCD) error { return lcd.LevelMeter(0, 50, 75) }},
+		{"Sparkline", func(lcd *I2CLCD) error { return lcd.Sparkline(0, 0, []int{1, 5, 3}, 4) }},
+		{"PrintInverse", func(lcd *I2CLCD) error { return lcd.PrintInverse(0, 0, "HI") }},
+		{"PrintHeading", func(lcd *I2CLCD) error { return lcd.PrintHeading(0, 0, 90) }},
+		{"ClockHand", func(lcd *I2CLCD) error { return lcd.ClockHand(0, 0, 3) }},
+		{"ToggleSwitch", func(lcd *I2CLCD) error { return lcd.ToggleSwitch(0, 0, true) }},
+		{"PrintEllipsis", func(lcd *I2CLCD) error { return lcd.PrintEllipsis(0, 0, 4, "too long") }},
+		{"PrintUnderlined", func(lcd *I2CLCD) error { return lcd.PrintUnderlined(0, 0, "hi") }},
+		{"PrintSevenSeg", func(lcd *I2CLCD) error { return lcd.PrintSevenSeg(0, 8) }},
+		{"PrintBits", func(lcd *I2CLCD) error { return lcd.PrintBits(0, 0, 5, 4) }},
+		{"BatteryWidget", func(lcd *I2CLCD) error { return lcd.BatteryWidget(0, 0, 60) }},
+		{"DrawGrid", func(lcd *I2CLCD) error { return lcd.DrawGrid([][]rune{[]rune("hi")}) }},
+		{"DrawBitmap", func(lcd *I2CLCD) error { return lcd.DrawBitmap(bmp) }},
+		{"PrintLarge", func(lcd *I2CLCD) error { return lcd.PrintLarge(0, 0, "A") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lcd, bus := newTestLCD(t, 16, 2)
+			bus.setFail(true)
+			if err := tt.call(lcd); err == nil {
+				t.Error("got nil error with a failing bus")
+			}
+
+			// Let the glyphs load, then fail on the first write to the screen
+			lcd, bus = newTestLCD(t, 16, 2)
+			ddram := false
+			lcd.SetTrace(func(value byte, isData bool) {
+				if !isData {
+					ddram = value&LCD_SETDDRAMADDR != 0
+				} else if ddram {
+					bus.setFail(true)
+				}
+			})
+			if err := tt.call(lcd); err == nil {
+				t.Error("got nil error when the bus failed after loading glyphs")
+			}
+		})
+	}
+}
+
+func TestPrintInverseErrors(t *testing.T) {
+	lcd, _ := newTestLCD(t, 16, 2)
+	if err := lcd.PrintInverse(0, 0, "ABCDEFGHI"); !errors.Is(err, ErrTooManyGlyphs) {
+		t.Errorf("nine distinct characters = %v, want ErrTooManyGlyphs", err)
+	}
+	for loc := byte(0); loc < 7; loc++ {
+		lcd.CreateChar(loc, userGlyph)
+	}
+	if err := lcd.PrintInverse(0, 0, "AB"); !errors.Is(err, ErrGlyphInUse) {
+		t.Errorf("two characters beside seven user glyphs = %v, want ErrGlyphInUse", err)
+	}
+	if err := lcd.PrintInverse(0, 0, "AA"); err != nil {
+		t.Errorf("one character beside seven user glyphs: %v", err)
+	}
+}